@@ -1,6 +1,9 @@
 package holdem
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+)
 
 var NameFromValue = map[int]string{
 	2:  "2",
@@ -58,3 +61,10 @@ func GetStandardDeck() []Card {
 	}
 	return standardDeck
 }
+
+// GetShortDeck returns the 36 card deck of the short-deck (6+) hold'em without twos to fives
+func GetShortDeck() []Card {
+	return slices.DeleteFunc(GetStandardDeck(), func(c Card) bool {
+		return c.Value < 6
+	})
+}
@@ -1,16 +1,31 @@
 package holdem
 
 import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"slices"
 	"sort"
 )
 
+var ErrInvalidCards = errors.New("hand must consist of 5 to 7 distinct cards of the standard deck")
+
+// HandRank is the category of a poker hand, a stronger category is greater
+type HandRank int
+
+// HandCategory is the former name of HandRank
+//
+// Deprecated: use HandRank.
+type HandCategory = HandRank
+
 type Combination struct {
-	Rank         int
+	Rank         HandRank
 	CompareCards []Card
+	shortDeck    bool // в short-deck флеш старше фулл-хауса
 }
 
 const (
-	HighCard = iota + 1
+	HighCard HandRank = iota + 1
 	OnePair
 	TwoPairs
 	ThreeOfAKind
@@ -22,27 +37,222 @@ const (
 	RoyalFlush
 )
 
-// EvaluateHand.
+var handRankNames = map[HandRank]string{
+	HighCard:      "high card",
+	OnePair:       "one pair",
+	TwoPairs:      "two pairs",
+	ThreeOfAKind:  "three of a kind",
+	Straight:      "straight",
+	Flush:         "flush",
+	FullHouse:     "full house",
+	FourOfAKind:   "four of a kind",
+	StraightFlush: "straight flush",
+	RoyalFlush:    "royal flush",
+}
+
+func (r HandRank) String() string {
+	if name, ok := handRankNames[r]; ok {
+		return name
+	}
+	return fmt.Sprintf("HandRank(%d)", int(r))
+}
+
+// Старшая карта младшего стрита, в котором туз идет за единицу
+const (
+	wheelTop          = 5 // A-2-3-4-5
+	shortDeckWheelTop = 9 // A-6-7-8-9 в short-deck
+)
+
+// score packs the rank and the compare cards into a single comparable number
+func (c Combination) score() int {
+	score := int(c.Rank)
+	if c.shortDeck && c.Rank == Flush {
+		score = int(FullHouse)
+	} else if c.shortDeck && c.Rank == FullHouse {
+		score = int(Flush)
+	}
+	for i := 0; i < 5; i++ {
+		score *= 15
+		if i < len(c.CompareCards) {
+			score += c.CompareCards[i].Value
+		}
+	}
+	return score
+}
+
+// EvaluateHand returns the rank of the best five card hand made of the hole and the community
+// cards together with these five cards: the cards making the combination first, then the kickers,
+// a straight goes from its top card. It accepts 5 to 7 cards in total.
+func EvaluateHand(hole []Card, community []Card) (HandRank, []Card, error) {
+	if err := validateCards(slices.Concat(hole, community)); err != nil {
+		return 0, nil, err
+	}
+	best, bestCards := bestFiveCards(hole, community, evaluate, false)
+	return best.Rank, bestCards, nil
+}
+
+// bestFiveCards returns the best combination by eval and the five cards making it, ordered
+// by significance. In Omaha the five cards are two hole and three community cards.
+func bestFiveCards(hole []Card, community []Card, eval evaluator, omaha bool) (Combination, []Card) {
+	var best Combination
+	var bestCards []Card
+	try := func(hand, board []Card) {
+		combination := eval(hand, board)
+		if bestCards == nil || combination.score() > best.score() {
+			five := slices.Concat(hand, board)
+			sort.SliceStable(five, func(i, j int) bool { return five[i].Value > five[j].Value })
+			best, bestCards = combination, five
+		}
+	}
+	if omaha {
+		forEachOmahaHand(hole, community, try)
+	} else {
+		cards := slices.Concat(hole, community)
+		for mask := 0; mask < 1<<len(cards); mask++ { // перебираем все пятерки карт
+			if bits.OnesCount(uint(mask)) != 5 {
+				continue
+			}
+			five := make([]Card, 0, 5)
+			for i, c := range cards {
+				if mask&(1<<i) != 0 {
+					five = append(five, c)
+				}
+			}
+			try(five, nil)
+		}
+	}
+	if bestCards == nil {
+		return best, nil
+	}
+	if len(best.CompareCards) == 5 { // для пар, сетов и каре карты идут в порядке значимости
+		bestCards = best.CompareCards
+	}
+	if (best.Rank == Straight || best.Rank == StraightFlush) && bestCards[0].Value == 14 && best.CompareCards[0].Value != 14 {
+		bestCards = append(bestCards[1:], bestCards[0]) // в младшем стрите туз идет за единицу
+	}
+	return best, bestCards
+}
+
+// HandValue is the strength of a hand: the rank and the card values deciding between hands of the rank
+type HandValue struct {
+	Rank     HandRank
+	Tiebreak []int
+}
+
+// EvaluateValue returns the value of the best hand made of 5 to 7 cards, it can be compared with CompareHands
+func EvaluateValue(hole []Card, community []Card) (HandValue, error) {
+	if err := validateCards(slices.Concat(hole, community)); err != nil {
+		return HandValue{}, err
+	}
+	combination := evaluate(hole, community)
+	value := HandValue{Rank: combination.Rank, Tiebreak: make([]int, 0, len(combination.CompareCards))}
+	for _, c := range combination.CompareCards {
+		value.Tiebreak = append(value.Tiebreak, c.Value)
+	}
+	return value, nil
+}
+
+// CompareHands returns 1 if the hand a is stronger than b, -1 if it is weaker and 0 on a split
+func CompareHands(a, b HandValue) int {
+	if a.Rank != b.Rank {
+		if a.Rank > b.Rank {
+			return 1
+		}
+		return -1
+	}
+	return slices.Compare(a.Tiebreak, b.Tiebreak)
+}
+
+// validateCards checks that the cards are 5 to 7 distinct cards of the standard deck
+func validateCards(cards []Card) error {
+	if len(cards) < 5 || len(cards) > 7 {
+		return ErrInvalidCards
+	}
+	deck := GetStandardDeck()
+	seen := make(map[Card]bool, len(cards))
+	for _, c := range cards {
+		if seen[c] || !slices.Contains(deck, c) {
+			return ErrInvalidCards
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// evaluator evaluates the best hand of the player by the rules of the variant
+type evaluator func(playerHand []Card, communityCards []Card) Combination
+
+// evaluateOmaha evaluates the best hand made of exactly two hole cards and exactly three community cards
+func evaluateOmaha(playerHand []Card, communityCards []Card) Combination {
+	return bestOmahaHand(playerHand, communityCards, evaluate)
+}
+
+// bestOmahaHand returns the best of the two plus three card hands evaluated by eval
+func bestOmahaHand(playerHand []Card, communityCards []Card, eval evaluator) Combination {
+	var best Combination
+	found := false
+	forEachOmahaHand(playerHand, communityCards, func(two, three []Card) {
+		combination := eval(two, three)
+		if !found || combination.score() > best.score() {
+			best, found = combination, true
+		}
+	})
+	return best
+}
+
+// forEachOmahaHand calls fn for every pair of the hole cards with every three community cards
+func forEachOmahaHand(hole []Card, community []Card, fn func(two, three []Card)) {
+	for i := 0; i < len(hole); i++ {
+		for j := i + 1; j < len(hole); j++ {
+			two := []Card{hole[i], hole[j]}
+			for a := 0; a < len(community); a++ {
+				for b := a + 1; b < len(community); b++ {
+					for c := b + 1; c < len(community); c++ {
+						fn(two, []Card{community[a], community[b], community[c]})
+					}
+				}
+			}
+		}
+	}
+}
+
+// evaluate.
 // Функция для определения комбинации из двух карт игрока (параметр playerHand) и пяти карт на столке (параметр communityCards)
-func EvaluateHand(playerHand []Card, communityCards []Card) Combination {
-	allCards := append(playerHand, communityCards...)
+func evaluate(playerHand []Card, communityCards []Card) Combination {
+	return evaluateHand(playerHand, communityCards, wheelTop)
+}
+
+// evaluateShortDeck evaluates the hand of the short-deck hold'em: A-6-7-8-9 is the lowest straight
+// and a flush beats a full house
+func evaluateShortDeck(playerHand []Card, communityCards []Card) Combination {
+	combination := evaluateHand(playerHand, communityCards, shortDeckWheelTop)
+	combination.shortDeck = true
+	return combination
+}
+
+// evaluateHand evaluates the hand of a variant whose lowest straight is the ace followed
+// by four cards up to lowStraightTop
+func evaluateHand(playerHand []Card, communityCards []Card, lowStraightTop int) Combination {
+	allCards := make([]Card, 0, len(playerHand)+len(communityCards)) // не сортируем срезы вызывающего
+	allCards = append(allCards, playerHand...)
+	allCards = append(allCards, communityCards...)
 	sort.Slice(allCards, func(i, j int) bool {
 		return allCards[i].Value > allCards[j].Value
 	})
 
 	flushCards := checkFlush(allCards)
 	if len(flushCards) >= 5 {
-		straightFlushCards := checkStraight(flushCards)
+		straightFlushCards := checkStraight(flushCards, lowStraightTop)
 		if len(straightFlushCards) >= 5 {
 			if straightFlushCards[0].Value == 14 {
 				return Combination{Rank: RoyalFlush, CompareCards: straightFlushCards[:1]} // Роял-флеш
 			}
 			return Combination{Rank: StraightFlush, CompareCards: straightFlushCards[:1]} // Стрит-флеш
 		}
-		return Combination{Rank: Flush, CompareCards: flushCards[:1]} // Флеш
+		return Combination{Rank: Flush, CompareCards: flushCards[:5]} // Флеш, сравнивается по всем пяти картам
 	}
 
-	straightCards := checkStraight(allCards)
+	straightCards := checkStraight(allCards, lowStraightTop)
 	if len(straightCards) >= 5 {
 		return Combination{Rank: Straight, CompareCards: straightCards[:1]} // Стрит
 	}
@@ -75,8 +285,9 @@ func EvaluateHand(playerHand []Card, communityCards []Card) Combination {
 
 	if len(threes) >= 2 {
 		threeCards := getCardsByValue(allCards, threes[0])
-		kicker := getKickers(allCards, threeCards, 2)
-		return Combination{Rank: FullHouse, CompareCards: append(threeCards, kicker...)} // Фулл-хаус
+		// пара берется из младшего сета
+		pairCards := getCardsByValue(allCards, threes[1])[:2]
+		return Combination{Rank: FullHouse, CompareCards: append(threeCards, pairCards...)} // Фулл-хаус
 	}
 
 	if len(threes) >= 1 && len(pairs) >= 1 {
@@ -104,7 +315,7 @@ func EvaluateHand(playerHand []Card, communityCards []Card) Combination {
 		return Combination{Rank: OnePair, CompareCards: append(pairCards, kickers...)} // Пара
 	}
 
-	return Combination{Rank: HighCard, CompareCards: allCards[:5]} // Старшая карта
+	return Combination{Rank: HighCard, CompareCards: allCards[:min(5, len(allCards))]} // Старшая карта, у одного борда карт может быть меньше
 }
 
 // Helper functions (unchanged)
@@ -124,7 +335,7 @@ func checkFlush(cards []Card) []Card {
 	return nil
 }
 
-func checkStraight(cards []Card) []Card {
+func checkStraight(cards []Card, lowStraightTop int) []Card {
 	uniqueValues := make(map[int]bool)
 	var uniqueCards []Card
 	for _, card := range cards {
@@ -157,7 +368,7 @@ func checkStraight(cards []Card) []Card {
 			hasAce = true
 			aceSuit = card.Suit
 		}
-		if card.Value == 5 || card.Value == 4 || card.Value == 3 || card.Value == 2 {
+		if card.Value <= lowStraightTop && card.Value > lowStraightTop-4 {
 			lowStraightCards = append(lowStraightCards, card)
 		}
 	}
@@ -197,3 +408,115 @@ func getKickers(cards []Card, exclude []Card, count int) []Card {
 	}
 	return kickers
 }
+
+// scoreSeven scores the hold'em hand of the standard deck like evaluate(hole, board).score(),
+// but on bit masks without maps and sorting: the exact equity enumerates millions of boards preflop
+func scoreSeven(hole []Card, board []Card) int {
+	var suits [4]uint16
+	var counts [15]int
+	values := uint16(0)
+	for _, cards := range [2][]Card{hole, board} {
+		for _, c := range cards {
+			suits[suitIndex(c.Suit)] |= 1 << c.Value
+			values |= 1 << c.Value
+			counts[c.Value]++
+		}
+	}
+	for _, mask := range suits {
+		if bits.OnesCount16(mask) < 5 {
+			continue
+		}
+		if top := straightTop(mask); top == 14 {
+			return packScore(RoyalFlush, top)
+		} else if top > 0 {
+			return packScore(StraightFlush, top)
+		}
+		return packScore(Flush, topValues(mask, 5)...)
+	}
+	if top := straightTop(values); top > 0 {
+		return packScore(Straight, top)
+	}
+
+	four, three, secondThree, pair, secondPair := 0, 0, 0, 0, 0
+	for v := 14; v >= 2; v-- {
+		switch {
+		case counts[v] == 4:
+			four = v
+		case counts[v] == 3 && three == 0:
+			three = v
+		case counts[v] == 3:
+			secondThree = v
+		case counts[v] == 2 && pair == 0:
+			pair = v
+		case counts[v] == 2 && secondPair == 0:
+			secondPair = v
+		}
+	}
+	switch {
+	case four > 0:
+		return packScore(FourOfAKind, four, four, four, four, topValues(values&^(1<<four), 1)[0])
+	case three > 0 && secondThree > 0: // пара берется из младшего сета
+		return packScore(FullHouse, three, three, three, secondThree, secondThree)
+	case three > 0 && pair > 0:
+		return packScore(FullHouse, three, three, three, pair, pair)
+	case three > 0:
+		kickers := topValues(values&^(1<<three), 2)
+		return packScore(ThreeOfAKind, three, three, three, kickers[0], kickers[1])
+	case secondPair > 0:
+		kicker := topValues(values&^(1<<pair|1<<secondPair), 1)[0]
+		return packScore(TwoPairs, pair, pair, secondPair, secondPair, kicker)
+	case pair > 0:
+		kickers := topValues(values&^(1<<pair), 3)
+		return packScore(OnePair, pair, pair, kickers[0], kickers[1], kickers[2])
+	}
+	return packScore(HighCard, topValues(values, 5)...)
+}
+
+// suitIndex numbers the suits of the standard deck by their first letter
+func suitIndex(suit string) int {
+	switch suit[0] {
+	case 'S':
+		return 0
+	case 'H':
+		return 1
+	case 'D':
+		return 2
+	}
+	return 3
+}
+
+// straightTop returns the top card of the highest straight among the values of the mask, 0 if there is none
+func straightTop(mask uint16) int {
+	for top := 14; top >= 6; top-- {
+		if run := uint16(0x1f) << (top - 4); mask&run == run {
+			return top
+		}
+	}
+	if wheel := uint16(1<<14 | 0x3c); mask&wheel == wheel { // A-2-3-4-5
+		return wheelTop
+	}
+	return 0
+}
+
+// topValues returns the n highest values of the mask, from the highest
+func topValues(mask uint16, n int) []int {
+	out := make([]int, 0, 5)
+	for v := 14; v >= 2 && len(out) < n; v-- {
+		if mask&(1<<v) != 0 {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// packScore packs the rank and the compare values like Combination.score
+func packScore(rank HandRank, values ...int) int {
+	score := int(rank)
+	for i := 0; i < 5; i++ {
+		score *= 15
+		if i < len(values) {
+			score += values[i]
+		}
+	}
+	return score
+}
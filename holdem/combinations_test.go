@@ -1,6 +1,7 @@
 package holdem
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -220,6 +221,10 @@ func TestEvaluateHandGood(t *testing.T) {
 				Rank: Flush,
 				CompareCards: []Card{
 					Card{Suit: "Diamonds", Value: 12},
+					Card{Suit: "Diamonds", Value: 10},
+					Card{Suit: "Diamonds", Value: 8},
+					Card{Suit: "Diamonds", Value: 6},
+					Card{Suit: "Diamonds", Value: 6},
 				},
 			},
 		},
@@ -386,8 +391,398 @@ func TestEvaluateHandGood(t *testing.T) {
 
 	for _, tCase := range cases {
 		t.Run(tCase.TestCaseName, func(t *testing.T) {
-			comb := EvaluateHand(tCase.PlayerHand, tCase.CommunityCards)
+			comb := evaluate(tCase.PlayerHand, tCase.CommunityCards)
 			require.Equal(t, comb, tCase.ExpectedCombination)
 		})
 	}
 }
+
+func TestStraightAceBothEnds(t *testing.T) {
+	cases := []struct {
+		TestCaseName   string
+		PlayerHand     []Card
+		CommunityCards []Card
+		LowStraightTop int
+		ExpectedRank   HandRank
+		ExpectedTop    int
+	}{
+		{
+			TestCaseName:   "Wheel",
+			PlayerHand:     []Card{{Suit: "Spades", Value: 14}, {Suit: "Clubs", Value: 2}},
+			CommunityCards: []Card{{Suit: "Hearts", Value: 3}, {Suit: "Clubs", Value: 4}, {Suit: "Diamonds", Value: 5}, {Suit: "Spades", Value: 9}, {Suit: "Spades", Value: 13}},
+			LowStraightTop: wheelTop,
+			ExpectedRank:   Straight,
+			ExpectedTop:    5,
+		},
+		{
+			TestCaseName:   "Broadway",
+			PlayerHand:     []Card{{Suit: "Spades", Value: 14}, {Suit: "Clubs", Value: 13}},
+			CommunityCards: []Card{{Suit: "Hearts", Value: 12}, {Suit: "Clubs", Value: 11}, {Suit: "Diamonds", Value: 10}, {Suit: "Spades", Value: 2}, {Suit: "Spades", Value: 3}},
+			LowStraightTop: wheelTop,
+			ExpectedRank:   Straight,
+			ExpectedTop:    14,
+		},
+		{
+			TestCaseName:   "Short_Deck_Low_Straight",
+			PlayerHand:     []Card{{Suit: "Spades", Value: 14}, {Suit: "Clubs", Value: 6}},
+			CommunityCards: []Card{{Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 8}, {Suit: "Diamonds", Value: 9}, {Suit: "Spades", Value: 12}, {Suit: "Hearts", Value: 12}},
+			LowStraightTop: shortDeckWheelTop,
+			ExpectedRank:   Straight,
+			ExpectedTop:    9,
+		},
+		{
+			TestCaseName:   "Short_Deck_Low_Straight_In_Standard_Deck",
+			PlayerHand:     []Card{{Suit: "Spades", Value: 14}, {Suit: "Clubs", Value: 6}},
+			CommunityCards: []Card{{Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 8}, {Suit: "Diamonds", Value: 9}, {Suit: "Spades", Value: 12}, {Suit: "Hearts", Value: 12}},
+			LowStraightTop: wheelTop,
+			ExpectedRank:   OnePair,
+			ExpectedTop:    12,
+		},
+		{
+			TestCaseName:   "Wheel_Straight_Flush",
+			PlayerHand:     []Card{{Suit: "Spades", Value: 14}, {Suit: "Spades", Value: 2}},
+			CommunityCards: []Card{{Suit: "Spades", Value: 3}, {Suit: "Spades", Value: 4}, {Suit: "Spades", Value: 5}, {Suit: "Hearts", Value: 9}, {Suit: "Clubs", Value: 13}},
+			LowStraightTop: wheelTop,
+			ExpectedRank:   StraightFlush,
+			ExpectedTop:    5,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.TestCaseName, func(t *testing.T) {
+			combination := evaluateHand(c.PlayerHand, c.CommunityCards, c.LowStraightTop)
+			require.Equal(t, c.ExpectedRank, combination.Rank)
+			require.Equal(t, c.ExpectedTop, combination.CompareCards[0].Value)
+		})
+	}
+}
+
+func TestEvaluateHandAPI(t *testing.T) {
+	cases := []struct {
+		TestCaseName string
+		Hole         []Card
+		Community    []Card
+		ExpectedRank HandRank
+		ExpectedBest []Card
+		ExpectedErr  error
+	}{
+		{
+			TestCaseName: "Five_Cards_Full_House",
+			Hole:         []Card{{Suit: "Spades", Value: 9}, {Suit: "Hearts", Value: 9}},
+			Community:    []Card{{Suit: "Clubs", Value: 9}, {Suit: "Clubs", Value: 4}, {Suit: "Hearts", Value: 4}},
+			ExpectedRank: FullHouse,
+			ExpectedBest: []Card{{Suit: "Spades", Value: 9}, {Suit: "Hearts", Value: 9}, {Suit: "Clubs", Value: 9}, {Suit: "Clubs", Value: 4}, {Suit: "Hearts", Value: 4}},
+		},
+		{
+			TestCaseName: "Six_Cards_Flush_Keeps_Highest",
+			Hole:         []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 3}},
+			Community:    []Card{{Suit: "Hearts", Value: 10}, {Suit: "Hearts", Value: 7}, {Suit: "Hearts", Value: 5}, {Suit: "Hearts", Value: 2}},
+			ExpectedRank: Flush,
+			ExpectedBest: []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 10}, {Suit: "Hearts", Value: 7}, {Suit: "Hearts", Value: 5}, {Suit: "Hearts", Value: 3}},
+		},
+		{
+			TestCaseName: "Seven_Cards_Two_Pairs_With_Kicker",
+			Hole:         []Card{{Suit: "Spades", Value: 13}, {Suit: "Hearts", Value: 12}},
+			Community:    []Card{{Suit: "Clubs", Value: 13}, {Suit: "Diamonds", Value: 12}, {Suit: "Hearts", Value: 5}, {Suit: "Spades", Value: 5}, {Suit: "Clubs", Value: 8}},
+			ExpectedRank: TwoPairs,
+			ExpectedBest: []Card{{Suit: "Spades", Value: 13}, {Suit: "Clubs", Value: 13}, {Suit: "Hearts", Value: 12}, {Suit: "Diamonds", Value: 12}, {Suit: "Clubs", Value: 8}},
+		},
+		{
+			TestCaseName: "Too_Few_Cards",
+			Hole:         []Card{{Suit: "Spades", Value: 13}, {Suit: "Hearts", Value: 12}},
+			Community:    []Card{{Suit: "Clubs", Value: 13}},
+			ExpectedErr:  ErrInvalidCards,
+		},
+		{
+			TestCaseName: "Duplicate_Card",
+			Hole:         []Card{{Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 13}},
+			Community:    []Card{{Suit: "Clubs", Value: 13}, {Suit: "Clubs", Value: 2}, {Suit: "Clubs", Value: 3}},
+			ExpectedErr:  ErrInvalidCards,
+		},
+		{
+			TestCaseName: "Unknown_Card",
+			Hole:         []Card{{Suit: "Stars", Value: 13}, {Suit: "Spades", Value: 1}},
+			Community:    []Card{{Suit: "Clubs", Value: 13}, {Suit: "Clubs", Value: 2}, {Suit: "Clubs", Value: 3}},
+			ExpectedErr:  ErrInvalidCards,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.TestCaseName, func(t *testing.T) {
+			rank, best, err := EvaluateHand(c.Hole, c.Community)
+			require.Equal(t, c.ExpectedErr, err)
+			require.Equal(t, c.ExpectedRank, rank)
+			require.Equal(t, c.ExpectedBest, best)
+		})
+	}
+
+	require.Equal(t, "full house", FullHouse.String())
+	require.Equal(t, "royal flush", RoyalFlush.String())
+	require.True(t, RoyalFlush > StraightFlush)
+}
+
+func TestEvaluateHandBestFive(t *testing.T) {
+	cases := []struct {
+		TestCaseName string
+		Hole         []Card
+		Community    []Card
+		ExpectedRank HandRank
+		ExpectedBest []Card
+	}{
+		{
+			TestCaseName: "Three_Pairs_Kicker_From_Third_Pair",
+			Hole:         []Card{{Suit: "Spades", Value: 13}, {Suit: "Hearts", Value: 12}},
+			Community:    []Card{{Suit: "Clubs", Value: 13}, {Suit: "Diamonds", Value: 12}, {Suit: "Hearts", Value: 5}, {Suit: "Spades", Value: 5}, {Suit: "Clubs", Value: 2}},
+			ExpectedRank: TwoPairs,
+			ExpectedBest: []Card{{Suit: "Spades", Value: 13}, {Suit: "Clubs", Value: 13}, {Suit: "Hearts", Value: 12}, {Suit: "Diamonds", Value: 12}, {Suit: "Hearts", Value: 5}},
+		},
+		{
+			TestCaseName: "Seven_Suited_Cards_Flush",
+			Hole:         []Card{{Suit: "Clubs", Value: 2}, {Suit: "Clubs", Value: 13}},
+			Community:    []Card{{Suit: "Clubs", Value: 4}, {Suit: "Clubs", Value: 9}, {Suit: "Clubs", Value: 6}, {Suit: "Clubs", Value: 11}, {Suit: "Clubs", Value: 3}},
+			ExpectedRank: Flush,
+			ExpectedBest: []Card{{Suit: "Clubs", Value: 13}, {Suit: "Clubs", Value: 11}, {Suit: "Clubs", Value: 9}, {Suit: "Clubs", Value: 6}, {Suit: "Clubs", Value: 4}},
+		},
+		{
+			TestCaseName: "Straight_Over_Paired_Board",
+			Hole:         []Card{{Suit: "Spades", Value: 9}, {Suit: "Hearts", Value: 8}},
+			Community:    []Card{{Suit: "Clubs", Value: 7}, {Suit: "Diamonds", Value: 6}, {Suit: "Hearts", Value: 5}, {Suit: "Spades", Value: 5}, {Suit: "Clubs", Value: 14}},
+			ExpectedRank: Straight,
+			ExpectedBest: []Card{{Suit: "Spades", Value: 9}, {Suit: "Hearts", Value: 8}, {Suit: "Clubs", Value: 7}, {Suit: "Diamonds", Value: 6}, {Suit: "Hearts", Value: 5}},
+		},
+		{
+			TestCaseName: "Wheel_Ace_Last",
+			Hole:         []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 2}},
+			Community:    []Card{{Suit: "Clubs", Value: 3}, {Suit: "Diamonds", Value: 4}, {Suit: "Hearts", Value: 5}, {Suit: "Spades", Value: 13}, {Suit: "Clubs", Value: 9}},
+			ExpectedRank: Straight,
+			ExpectedBest: []Card{{Suit: "Hearts", Value: 5}, {Suit: "Diamonds", Value: 4}, {Suit: "Clubs", Value: 3}, {Suit: "Hearts", Value: 2}, {Suit: "Spades", Value: 14}},
+		},
+		{
+			TestCaseName: "Two_Trips_Full_House",
+			Hole:         []Card{{Suit: "Spades", Value: 7}, {Suit: "Hearts", Value: 7}},
+			Community:    []Card{{Suit: "Clubs", Value: 7}, {Suit: "Diamonds", Value: 10}, {Suit: "Hearts", Value: 10}, {Suit: "Spades", Value: 10}, {Suit: "Clubs", Value: 14}},
+			ExpectedRank: FullHouse,
+			ExpectedBest: []Card{{Suit: "Diamonds", Value: 10}, {Suit: "Hearts", Value: 10}, {Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 7}, {Suit: "Hearts", Value: 7}},
+		},
+		{
+			TestCaseName: "Quads_Highest_Kicker",
+			Hole:         []Card{{Suit: "Spades", Value: 3}, {Suit: "Hearts", Value: 3}},
+			Community:    []Card{{Suit: "Clubs", Value: 3}, {Suit: "Diamonds", Value: 3}, {Suit: "Hearts", Value: 10}, {Suit: "Spades", Value: 10}, {Suit: "Clubs", Value: 12}},
+			ExpectedRank: FourOfAKind,
+			ExpectedBest: []Card{{Suit: "Spades", Value: 3}, {Suit: "Hearts", Value: 3}, {Suit: "Clubs", Value: 3}, {Suit: "Diamonds", Value: 3}, {Suit: "Clubs", Value: 12}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.TestCaseName, func(t *testing.T) {
+			rank, best, err := EvaluateHand(c.Hole, c.Community)
+			require.NoError(t, err)
+			require.Equal(t, c.ExpectedRank, rank)
+			require.Equal(t, c.ExpectedBest, best)
+		})
+	}
+}
+
+func TestWheel(t *testing.T) {
+	board := []Card{{Suit: "Hearts", Value: 3}, {Suit: "Clubs", Value: 4}, {Suit: "Diamonds", Value: 5}, {Suit: "Spades", Value: 13}, {Suit: "Clubs", Value: 13}}
+	wheel := evaluate([]Card{{Suit: "Spades", Value: 14}, {Suit: "Clubs", Value: 2}}, board)
+	sixHigh := evaluate([]Card{{Suit: "Hearts", Value: 2}, {Suit: "Clubs", Value: 6}}, board)
+	trips := evaluate([]Card{{Suit: "Hearts", Value: 13}, {Suit: "Diamonds", Value: 14}}, board)
+	require.Equal(t, Straight, wheel.Rank)
+	require.Equal(t, 5, wheel.CompareCards[0].Value)
+	require.Less(t, wheel.score(), sixHigh.score())
+	require.Greater(t, wheel.score(), trips.score())
+
+	suited := []Card{{Suit: "Spades", Value: 3}, {Suit: "Spades", Value: 4}, {Suit: "Spades", Value: 5}, {Suit: "Hearts", Value: 9}, {Suit: "Clubs", Value: 9}}
+	steelWheel := evaluate([]Card{{Suit: "Spades", Value: 14}, {Suit: "Spades", Value: 2}}, suited)
+	sixHighFlush := evaluate([]Card{{Suit: "Spades", Value: 2}, {Suit: "Spades", Value: 6}}, suited)
+	quads := evaluate([]Card{{Suit: "Spades", Value: 9}, {Suit: "Diamonds", Value: 9}}, suited)
+	require.Equal(t, StraightFlush, steelWheel.Rank)
+	require.Equal(t, 5, steelWheel.CompareCards[0].Value)
+	require.Less(t, steelWheel.score(), sixHighFlush.score())
+	require.Greater(t, steelWheel.score(), quads.score())
+
+	winners, err := DeterminateWinner(append(board[:3:3], Card{Suit: "Hearts", Value: 10}, Card{Suit: "Clubs", Value: 12}), map[string]IPlayer{
+		"wheel":   &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 14}, {Suit: "Clubs", Value: 2}}}},
+		"sixHigh": &Player{Hand: Hand{[]Card{{Suit: "Hearts", Value: 2}, {Suit: "Clubs", Value: 6}}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"sixHigh"}, winners)
+}
+
+func TestCompareHands(t *testing.T) {
+	// по одной руке каждой категории, от слабой к сильной
+	hands := []struct {
+		Rank      HandRank
+		Hole      []Card
+		Community []Card
+	}{
+		{HighCard, []Card{{Suit: "Clubs", Value: 2}, {Suit: "Spades", Value: 7}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Diamonds", Value: 9}}},
+		{OnePair, []Card{{Suit: "Clubs", Value: 14}, {Suit: "Spades", Value: 7}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Diamonds", Value: 9}}},
+		{TwoPairs, []Card{{Suit: "Clubs", Value: 14}, {Suit: "Spades", Value: 13}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Diamonds", Value: 9}}},
+		{ThreeOfAKind, []Card{{Suit: "Clubs", Value: 14}, {Suit: "Spades", Value: 14}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Diamonds", Value: 9}}},
+		{Straight, []Card{{Suit: "Clubs", Value: 11}, {Suit: "Spades", Value: 10}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Diamonds", Value: 12}}},
+		{Flush, []Card{{Suit: "Hearts", Value: 2}, {Suit: "Hearts", Value: 7}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Hearts", Value: 9}}},
+		{FullHouse, []Card{{Suit: "Clubs", Value: 14}, {Suit: "Spades", Value: 14}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Diamonds", Value: 13}}},
+		{FourOfAKind, []Card{{Suit: "Clubs", Value: 14}, {Suit: "Spades", Value: 14}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Diamonds", Value: 14}, {Suit: "Hearts", Value: 13}}},
+		{StraightFlush, []Card{{Suit: "Hearts", Value: 10}, {Suit: "Hearts", Value: 9}}, []Card{{Suit: "Hearts", Value: 13}, {Suit: "Hearts", Value: 12}, {Suit: "Hearts", Value: 11}}},
+		{RoyalFlush, []Card{{Suit: "Hearts", Value: 11}, {Suit: "Hearts", Value: 10}}, []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Hearts", Value: 12}}},
+	}
+	values := make([]HandValue, 0, len(hands))
+	for _, h := range hands {
+		v, err := EvaluateValue(h.Hole, h.Community)
+		require.NoError(t, err)
+		require.Equal(t, h.Rank, v.Rank)
+		values = append(values, v)
+	}
+	for i := 1; i < len(values); i++ {
+		require.Equal(t, 1, CompareHands(values[i], values[i-1]), hands[i].Rank.String())
+		require.Equal(t, -1, CompareHands(values[i-1], values[i]), hands[i].Rank.String())
+	}
+
+	kickers := []struct {
+		TestCaseName string
+		A            []Card
+		B            []Card
+		Community    []Card
+		Expected     int
+	}{
+		{
+			TestCaseName: "Pair_Third_Kicker",
+			A:            []Card{{Suit: "Clubs", Value: 10}, {Suit: "Spades", Value: 6}},
+			B:            []Card{{Suit: "Diamonds", Value: 10}, {Suit: "Hearts", Value: 5}},
+			Community:    []Card{{Suit: "Hearts", Value: 10}, {Suit: "Clubs", Value: 13}, {Suit: "Spades", Value: 12}},
+			Expected:     1,
+		},
+		{
+			TestCaseName: "Two_Pairs_Kicker",
+			A:            []Card{{Suit: "Clubs", Value: 9}, {Suit: "Spades", Value: 8}, {Suit: "Clubs", Value: 3}},
+			B:            []Card{{Suit: "Diamonds", Value: 9}, {Suit: "Hearts", Value: 8}, {Suit: "Diamonds", Value: 4}},
+			Community:    []Card{{Suit: "Hearts", Value: 9}, {Suit: "Clubs", Value: 8}},
+			Expected:     -1,
+		},
+		{
+			TestCaseName: "Kicker_Below_Board_Splits",
+			A:            []Card{{Suit: "Clubs", Value: 2}, {Suit: "Spades", Value: 3}},
+			B:            []Card{{Suit: "Diamonds", Value: 2}, {Suit: "Hearts", Value: 4}},
+			Community:    []Card{{Suit: "Hearts", Value: 14}, {Suit: "Clubs", Value: 14}, {Suit: "Spades", Value: 13}, {Suit: "Diamonds", Value: 12}, {Suit: "Clubs", Value: 9}},
+			Expected:     0,
+		},
+		{
+			TestCaseName: "Flush_Last_Card",
+			A:            []Card{{Suit: "Hearts", Value: 3}, {Suit: "Spades", Value: 14}},
+			B:            []Card{{Suit: "Hearts", Value: 2}, {Suit: "Spades", Value: 13}},
+			Community:    []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 10}, {Suit: "Hearts", Value: 8}, {Suit: "Hearts", Value: 6}},
+			Expected:     1,
+		},
+		{
+			TestCaseName: "Full_House_Two_Sets",
+			A:            []Card{{Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 12}},
+			B:            []Card{{Suit: "Diamonds", Value: 13}, {Suit: "Diamonds", Value: 11}},
+			Community:    []Card{{Suit: "Hearts", Value: 13}, {Suit: "Clubs", Value: 13}, {Suit: "Hearts", Value: 12}, {Suit: "Clubs", Value: 12}, {Suit: "Diamonds", Value: 14}},
+			Expected:     0,
+		},
+	}
+	for _, c := range kickers {
+		t.Run(c.TestCaseName, func(t *testing.T) {
+			a, err := EvaluateValue(c.A, c.Community)
+			require.NoError(t, err)
+			b, err := EvaluateValue(c.B, c.Community)
+			require.NoError(t, err)
+			require.Equal(t, c.Expected, CompareHands(a, b))
+		})
+	}
+
+	_, err := EvaluateValue([]Card{{Suit: "Clubs", Value: 2}}, hands[0].Community)
+	require.Equal(t, ErrInvalidCards, err)
+}
+
+func TestLowHandRank(t *testing.T) {
+	low, ok := LowHandRank([]Card{
+		{Suit: "Hearts", Value: 14}, {Suit: "Clubs", Value: 2}, {Suit: "Spades", Value: 2},
+		{Suit: "Hearts", Value: 5}, {Suit: "Diamonds", Value: 7}, {Suit: "Clubs", Value: 8}, {Suit: "Clubs", Value: 3},
+	})
+	require.True(t, ok)
+	require.Equal(t, LowHand{7, 5, 3, 2, 1}, low)
+
+	_, ok = LowHandRank([]Card{
+		{Suit: "Hearts", Value: 14}, {Suit: "Clubs", Value: 2}, {Suit: "Spades", Value: 9},
+		{Suit: "Hearts", Value: 5}, {Suit: "Diamonds", Value: 13}, {Suit: "Clubs", Value: 8}, {Suit: "Clubs", Value: 5},
+	})
+	require.False(t, ok)
+
+	require.Equal(t, 1, compareLow(LowHand{6, 4, 3, 2, 1}, LowHand{6, 5, 3, 2, 1}))
+	require.Equal(t, -1, compareLow(LowHand{8, 4, 3, 2, 1}, LowHand{7, 6, 5, 4, 3}))
+}
+
+func TestEvaluateOmaha(t *testing.T) {
+	fourSpades := []Card{{Suit: "Spades", Value: 14}, {Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 12}, {Suit: "Spades", Value: 11}}
+	oneSpade := []Card{{Suit: "Spades", Value: 2}, {Suit: "Hearts", Value: 7}, {Suit: "Diamonds", Value: 8}, {Suit: "Clubs", Value: 9}, {Suit: "Hearts", Value: 10}}
+	threeSpades := []Card{{Suit: "Spades", Value: 2}, {Suit: "Spades", Value: 7}, {Suit: "Spades", Value: 8}, {Suit: "Clubs", Value: 9}, {Suit: "Hearts", Value: 10}}
+
+	// в холдеме четыре пики на руках и одна на борде дают флеш
+	require.Equal(t, Flush, evaluate(fourSpades, oneSpade).Rank)
+	// в омахе берутся только две пики с руки: флеша нет, лучшее - стрит Q-J-10-9-8
+	combination := evaluateOmaha(fourSpades, oneSpade)
+	require.Equal(t, Straight, combination.Rank)
+	require.Equal(t, 12, combination.CompareCards[0].Value)
+	// две пики с руки и три с борда собирают флеш
+	require.Equal(t, Flush, evaluateOmaha(fourSpades, threeSpades).Rank)
+	require.Equal(t, 14, evaluateOmaha(fourSpades, threeSpades).CompareCards[0].Value)
+
+	// у второго игрока одна пика на руках, флеш ему недоступен
+	first := &Player{Hand: Hand{fourSpades}}
+	second := &Player{Hand: Hand{[]Card{
+		{Suit: "Spades", Value: 3}, {Suit: "Hearts", Value: 10}, {Suit: "Diamonds", Value: 10}, {Suit: "Clubs", Value: 2}}}}
+	winners, err := DeterminateOmahaWinner(threeSpades, map[string]IPlayer{"first": first, "second": second})
+	require.NoError(t, err)
+	require.Equal(t, []string{"first"}, winners)
+}
+
+func TestShortDeck(t *testing.T) {
+	deck := GetShortDeck()
+	require.Len(t, deck, 36)
+	for _, c := range deck {
+		require.GreaterOrEqual(t, c.Value, 6)
+	}
+
+	board := []Card{{Suit: "Hearts", Value: 9}, {Suit: "Hearts", Value: 13}, {Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 13}, {Suit: "Spades", Value: 6}}
+	flush := []Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 10}}
+	fullHouse := []Card{{Suit: "Diamonds", Value: 9}, {Suit: "Spades", Value: 9}}
+	require.Equal(t, Flush, evaluateShortDeck(flush, board).Rank)
+	require.Equal(t, FullHouse, evaluateShortDeck(fullHouse, board).Rank)
+	// в обычном холдеме фулл-хаус старше флеша, в short-deck наоборот
+	require.Greater(t, evaluate(fullHouse, board).score(), evaluate(flush, board).score())
+	require.Greater(t, evaluateShortDeck(flush, board).score(), evaluateShortDeck(fullHouse, board).score())
+
+	// A-6-7-8-9 - младший стрит, он проигрывает 6-7-8-9-10
+	wheel := evaluateShortDeck([]Card{{Suit: "Spades", Value: 14}, {Suit: "Diamonds", Value: 8}}, board)
+	require.Equal(t, Straight, wheel.Rank)
+	require.Equal(t, 9, wheel.CompareCards[0].Value)
+	tenHigh := evaluateShortDeck([]Card{{Suit: "Spades", Value: 10}, {Suit: "Diamonds", Value: 8}}, board)
+	require.Equal(t, Straight, tenHigh.Rank)
+	require.Greater(t, tenHigh.score(), wheel.score())
+}
+
+func TestScoreSeven(t *testing.T) {
+	r := rand.New(rand.NewSource(1488))
+	for i := 0; i < 20000; i++ {
+		deck := GetStandardDeck()
+		r.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+		hole, board := deck[:2], deck[2:7]
+		require.Equal(t, evaluate(hole, board).score(), scoreSeven(hole, board), "%v %v", hole, board)
+	}
+
+	// редкие руки случайная раздача почти не дает
+	hands := [][]Card{
+		{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Hearts", Value: 12}, {Suit: "Hearts", Value: 11}, {Suit: "Hearts", Value: 10}, {Suit: "Clubs", Value: 2}, {Suit: "Clubs", Value: 3}},
+		{{Suit: "Spades", Value: 14}, {Suit: "Spades", Value: 2}, {Suit: "Spades", Value: 3}, {Suit: "Spades", Value: 4}, {Suit: "Spades", Value: 5}, {Suit: "Hearts", Value: 6}, {Suit: "Clubs", Value: 13}},
+		{{Suit: "Spades", Value: 9}, {Suit: "Hearts", Value: 9}, {Suit: "Clubs", Value: 9}, {Suit: "Diamonds", Value: 9}, {Suit: "Spades", Value: 4}, {Suit: "Hearts", Value: 4}, {Suit: "Clubs", Value: 4}},
+		{{Suit: "Spades", Value: 8}, {Suit: "Hearts", Value: 8}, {Suit: "Clubs", Value: 8}, {Suit: "Diamonds", Value: 3}, {Suit: "Spades", Value: 3}, {Suit: "Hearts", Value: 3}, {Suit: "Clubs", Value: 14}},
+		{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 2}, {Suit: "Clubs", Value: 3}, {Suit: "Diamonds", Value: 4}, {Suit: "Spades", Value: 5}, {Suit: "Hearts", Value: 12}, {Suit: "Clubs", Value: 12}},
+		{{Suit: "Spades", Value: 7}, {Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 5}, {Suit: "Diamonds", Value: 5}, {Suit: "Spades", Value: 3}, {Suit: "Hearts", Value: 3}, {Suit: "Clubs", Value: 2}},
+	}
+	for _, cards := range hands {
+		require.Equal(t, evaluate(cards[:2], cards[2:]).score(), scoreSeven(cards[:2], cards[2:]), "%v", cards)
+	}
+}
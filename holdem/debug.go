@@ -0,0 +1,203 @@
+package holdem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+var ErrStreetNotReached = errors.New("hand ended before the street")
+
+type Move struct {
+	PlayerId string
+	Action   string
+	Amount   int
+}
+
+type Seat struct {
+	PlayerId string
+	Balance  int
+	Queued   bool // ждал в очереди и садится за стол в этой раздаче
+}
+
+// HandHistory contains everything needed to reproduce a hand: the seed and the shuffled deck,
+// the table settings, the seating and the state of the table at the moment of StartGame
+// and every move accepted by MakeMove.
+type HandHistory struct {
+	Seed         int64
+	Config       TableConfig
+	SmallBlind   int
+	Ante         int
+	DealerIndex  int
+	Blinds       BlindPositions
+	HandsPlayed  int
+	HandsAtLevel int
+	BlindLevel   int
+	StartedAt    time.Time
+	Time         time.Time // время часов стола при старте раздачи, по нему повтор поднимает блайнды
+	SittingOut   map[string]bool
+	Disconnected map[string]bool
+	Deck         []Card // колода после тасовки, в том числе от Shuffler или SetRand
+	Seats        []Seat
+	Moves        []Move
+}
+
+// fixedClock stops the time of a replayed table at the start of the recorded hand
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// recordedDeck deals the deck recorded in the history instead of shuffling a new one
+type recordedDeck []Card
+
+func (d recordedDeck) Shuffle([]Card) ([]Card, []byte, error) {
+	return slices.Clone(d), nil, nil
+}
+
+func (t *PokerTable) recordHandStart() {
+	seats := make([]Seat, 0, len(t.Meta.PlayersOrder)+len(t.Meta.Query))
+	for _, k := range t.Meta.PlayersOrder {
+		seats = append(seats, Seat{PlayerId: k, Balance: t.Meta.Players[k].GetBalance()})
+	}
+	for _, k := range t.Meta.QueryOrder {
+		seats = append(seats, Seat{PlayerId: k, Balance: t.Meta.Query[k].GetBalance(), Queued: true})
+	}
+	t.history = HandHistory{
+		Seed:         t.Meta.Seed,
+		Config:       *t.Config,
+		SmallBlind:   t.Meta.SmallBlind,
+		Ante:         t.Meta.Ante,
+		DealerIndex:  t.Meta.DealerIndex,
+		Blinds:       t.Meta.Blinds,
+		HandsPlayed:  t.Meta.HandsPlayed,
+		HandsAtLevel: t.Meta.HandsAtLevel,
+		BlindLevel:   t.Meta.BlindLevel,
+		StartedAt:    t.Meta.StartedAt,
+		Time:         t.now(),
+		SittingOut:   maps.Clone(t.Meta.SittingOut),
+		Disconnected: maps.Clone(t.Meta.Disconnected),
+		Deck:         slices.Clone(t.Meta.Deck),
+		Seats:        seats,
+		Moves:        []Move{},
+	}
+}
+
+func (t *PokerTable) recordMove(playerId, action string, amount int) {
+	t.history.Moves = append(t.history.Moves, Move{PlayerId: playerId, Action: action, Amount: amount})
+}
+
+// Debug serializes the last started hand so it can be reproduced with ReplayDebug.
+func (t *PokerTable) Debug() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data, _ := json.Marshal(t.history)
+	return data
+}
+
+// ReplayDebug restores the table captured by Debug and replays all recorded moves.
+func ReplayDebug(data []byte) (*PokerTable, error) {
+	var history HandHistory
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, err
+	}
+	return replayHistory(history, func(*PokerTable) bool { return false })
+}
+
+// ReplayUntil restores the hand from the history and replays it up to the start of the street
+func ReplayUntil(history HandHistory, street Street) (*PokerTable, error) {
+	table, err := replayHistory(history, func(t *PokerTable) bool {
+		return t.Meta.GameStarted && t.Meta.CurrentRound >= int(street)
+	})
+	if err != nil {
+		return table, err
+	}
+	if !table.Meta.GameStarted || table.Meta.CurrentRound < int(street) {
+		return table, ErrStreetNotReached
+	}
+	return table, nil
+}
+
+// replayHistory restores the table as it was at the start of the hand and replays the moves
+// of the history until stop returns true. The clock of the replay stays at the start of the hand.
+func replayHistory(history HandHistory, stop func(*PokerTable) bool) (*PokerTable, error) {
+	config := history.Config
+	config.MoveTimeout = 0        // повтор не должен ходить за игроков сам
+	config.InsurancePause = false // страховка не записывается, борд открывается сразу
+	config.Shuffler = nil
+	if len(history.Deck) > 0 {
+		config.Shuffler = recordedDeck(history.Deck)
+	}
+	meta := NewTableMeta(history.SmallBlind, history.Ante, history.Seed)
+	meta.DealerIndex = history.DealerIndex
+	meta.Blinds = history.Blinds
+	meta.HandsPlayed, meta.HandsAtLevel, meta.BlindLevel = history.HandsPlayed, history.HandsAtLevel, history.BlindLevel
+	meta.StartedAt = history.StartedAt
+	maps.Copy(meta.SittingOut, history.SittingOut)
+	// стеки ставятся как есть, без проверок бай-ина и стартового стека турнира
+	for _, seat := range history.Seats {
+		p := (&savedPlayer{Id: seat.PlayerId, Balance: seat.Balance}).player()
+		if seat.Queued {
+			meta.addPlayerInQuery(p)
+			continue
+		}
+		meta.addPlayerInGame(p)
+		meta.PlayersOrder = append(meta.PlayersOrder, p.GetId())
+	}
+	table := NewPokerTable(&config, meta)
+	table.SetClock(fixedClock(history.Time))
+	if err := table.StartGame(); err != nil {
+		return nil, err
+	}
+	// автоматические ходы за отключенных игроков уже записаны, флаги ставятся после них
+	defer maps.Copy(table.Meta.Disconnected, history.Disconnected)
+	for i, move := range history.Moves {
+		if stop(table) {
+			break
+		}
+		if err := table.MakeMove(move.PlayerId, move.Action, move.Amount); err != nil {
+			return table, fmt.Errorf("move %d %s %s %d: %w", i+1, move.PlayerId, move.Action, move.Amount, err)
+		}
+	}
+	return table, nil
+}
+
+// Replay deals the last started hand of the table again from the seed and applies the moves.
+// The seating, balances, settings and clock are taken from the start of that hand. With the seed
+// of that hand the recorded deck is dealt, even if it came from a Shuffler, any other seed
+// shuffles a new deck. The first illegal move stops the replay and is reported with its number.
+func (t *PokerTable) Replay(seed int64, moves []Move) (*PokerTable, error) {
+	t.mu.Lock()
+	history := t.history
+	t.mu.Unlock()
+	if seed != history.Seed {
+		history.Seed = seed
+		history.Deck = nil // колода тасуется заново по переданному сиду
+	}
+	history.Moves = moves
+	return replayHistory(history, func(*PokerTable) bool { return false })
+}
+
+// DryRun applies the moves to a copy of the table and returns the resulting meta and the first
+// error encountered. The table itself and its observers are left untouched.
+func (t *PokerTable) DryRun(moves []Move) (TableMeta, error) {
+	t.mu.Lock()
+	config := *t.Config
+	config.MoveTimeout = 0 // копия не должна ходить за игроков сама
+	clone := NewPokerTable(&config, t.Meta.clone())
+	clone.antePosted, clone.runTwice, clone.runTwiceFrom = t.antePosted, t.runTwice, t.runTwiceFrom
+	clone.history = t.history
+	clone.history.Seats = slices.Clone(t.history.Seats) // по ним расставляются вылетевшие
+	clone.history.Moves = slices.Clone(t.history.Moves)
+	t.mu.Unlock()
+	for _, move := range moves {
+		if err := clone.MakeMove(move.PlayerId, move.Action, move.Amount); err != nil {
+			return *clone.Meta, err
+		}
+	}
+	return *clone.Meta, nil
+}
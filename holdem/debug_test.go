@@ -0,0 +1,319 @@
+package holdem
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugReplay(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.NoError(t, table.StartGame())
+	table.MakeMove(p2.GetId(), "call", 0)
+	table.MakeMove(p3.GetId(), "call", 0)
+	table.MakeMove(p1.GetId(), "call", 0)
+	table.MakeMove(p3.GetId(), "raise", 200)
+	table.MakeMove(p1.GetId(), "fold", 0)
+	table.MakeMove(p2.GetId(), "call", 0)
+	playHand(t, table)
+
+	replayed, err := ReplayDebug(table.Debug())
+	require.NoError(t, err)
+	require.False(t, replayed.Meta.GameStarted)
+	for _, p := range []*Player{p1, p2, p3} {
+		require.Equal(t, p.GetBalance(), replayed.Meta.Players[p.GetId()].GetBalance())
+	}
+	require.Equal(t, table.Meta.CommunityCards, replayed.Meta.CommunityCards)
+}
+
+func TestReplayDebugBadData(t *testing.T) {
+	_, err := ReplayDebug([]byte("not a blob"))
+	require.Error(t, err)
+}
+
+func TestDryRun(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	cases := []struct {
+		TestCaseName  string
+		Moves         []Move
+		ExpectedErr   error
+		ExpectedRound int
+	}{
+		{
+			TestCaseName: "Legal line",
+			Moves: []Move{
+				{PlayerId: p2.GetId(), Action: "call"},
+				{PlayerId: p3.GetId(), Action: "call"},
+				{PlayerId: p1.GetId(), Action: "call"},
+				{PlayerId: p3.GetId(), Action: "raise", Amount: 200},
+			},
+			ExpectedErr:   nil,
+			ExpectedRound: 1,
+		},
+		{
+			TestCaseName: "Illegal line",
+			Moves: []Move{
+				{PlayerId: p2.GetId(), Action: "call"},
+				{PlayerId: p1.GetId(), Action: "call"},
+			},
+			ExpectedErr:   ErrNotYourTurn,
+			ExpectedRound: 0,
+		},
+	}
+	for _, tCase := range cases {
+		t.Run(tCase.TestCaseName, func(t *testing.T) {
+			res, err := table.DryRun(tCase.Moves)
+			require.Equal(t, tCase.ExpectedErr, err)
+			require.Equal(t, tCase.ExpectedRound, res.CurrentRound)
+
+			require.Equal(t, 0, table.Meta.CurrentRound)
+			require.Equal(t, p2.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+			require.Equal(t, 1000, p2.GetBalance())
+			require.Equal(t, 950, p3.GetBalance())
+		})
+	}
+}
+
+func TestReplayUntil(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+	history := table.history
+
+	replayed, err := ReplayUntil(history, Flop)
+	require.NoError(t, err)
+	require.Equal(t, int(Flop), replayed.Meta.CurrentRound)
+	require.Equal(t, history.Moves[:3], replayed.history.Moves)
+	require.Len(t, replayed.Meta.CommunityCards, 3)
+	require.Equal(t, table.Meta.CommunityCards[:3], replayed.Meta.CommunityCards)
+	// после флопа первым ходит малый блайнд
+	require.Equal(t, p3.GetId(), replayed.Meta.PlayersOrder[replayed.Meta.PlayerTurnInd])
+
+	history.Moves = history.Moves[:2]
+	_, err = ReplayUntil(history, Flop)
+	require.Equal(t, ErrStreetNotReached, err)
+}
+
+func TestReplay(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	seed := table.Meta.Seed
+	moves := []Move{
+		{PlayerId: p2.GetId(), Action: "raise", Amount: 300},
+		{PlayerId: p3.GetId(), Action: "call"},
+		{PlayerId: p1.GetId(), Action: "fold"},
+		{PlayerId: p3.GetId(), Action: "check"},
+		{PlayerId: p2.GetId(), Action: "bet", Amount: 200},
+		{PlayerId: p3.GetId(), Action: "call"},
+		{PlayerId: p3.GetId(), Action: "check"},
+		{PlayerId: p2.GetId(), Action: "check"},
+		{PlayerId: p3.GetId(), Action: "allin"},
+		{PlayerId: p2.GetId(), Action: "call"},
+	}
+	require.NoError(t, table.StartGame())
+	for _, move := range moves {
+		require.NoError(t, table.MakeMove(move.PlayerId, move.Action, move.Amount))
+	}
+	require.False(t, table.Meta.GameStarted)
+
+	replayed, err := table.Replay(seed, moves)
+	require.NoError(t, err)
+	require.False(t, replayed.Meta.GameStarted)
+	require.Equal(t, table.Meta.CommunityCards, replayed.Meta.CommunityCards)
+	for _, p := range []*Player{p1, p2, p3} {
+		require.Equal(t, p.GetBalance(), replayed.Meta.Players[p.GetId()].GetBalance())
+	}
+
+	// недопустимый ход останавливает повтор
+	bad := slices.Clone(moves)
+	bad[3] = Move{PlayerId: p2.GetId(), Action: "check"}
+	replayed, err = table.Replay(seed, bad)
+	require.ErrorIs(t, err, ErrNotYourTurn)
+	require.ErrorContains(t, err, "move 4")
+	require.Equal(t, RoundFlop, replayed.Meta.CurrentRound)
+}
+
+func TestDryRunKeepsHandState(t *testing.T) {
+	aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+	junk := []Card{{Suit: "Clubs", Value: 7}, {Suit: "Diamonds", Value: 8}}
+	junk2 := []Card{{Suit: "Clubs", Value: 9}, {Suit: "Diamonds", Value: 10}}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, 1000, false)
+	config.Shuffler = &stackedShuffler{tops: [][]Card{{}, slices.Concat(aces, junk, junk2)}}
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001")}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002")}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003")}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "fold", 0))
+
+	// у p2 и p3 разные стеки на начало раздачи, вылетевшие расставляются по ним
+	require.NoError(t, table.StartGame())
+	moves := []Move{
+		{PlayerId: p3.GetId(), Action: "allin"},
+		{PlayerId: p1.GetId(), Action: "allin"},
+		{PlayerId: p2.GetId(), Action: "allin"},
+	}
+	res, err := table.DryRun(moves)
+	require.NoError(t, err)
+	for _, move := range moves {
+		require.NoError(t, table.MakeMove(move.PlayerId, move.Action, move.Amount))
+	}
+	require.Equal(t, []string{p3.GetId(), p2.GetId()}, table.Meta.Eliminated)
+	require.Equal(t, table.Meta.Eliminated, res.Eliminated)
+}
+
+func TestReplayDebugRestoresTableState(t *testing.T) {
+	cases := []struct {
+		TestCaseName string
+		Configure    func(table *PokerTable)
+	}{
+		// на второй раздаче турнира стеки не сбрасываются к BankAmount
+		{TestCaseName: "Tournament", Configure: func(table *PokerTable) { table.Config.BankAmount = 1000 }},
+		// выигравший больше MaxBuyIn садится за стол со своим стеком
+		{TestCaseName: "Buy-in limits", Configure: func(table *PokerTable) { table.Config.MinBuyIn, table.Config.MaxBuyIn = 950, 1000 }},
+		// часы стола давно в прошлом, повтор не поднимает блайнды по настоящему времени
+		{TestCaseName: "Past clock", Configure: func(table *PokerTable) {}},
+		// колоду тасовал не сид, повтор раздает записанную колоду
+		{TestCaseName: "External shuffle", Configure: func(table *PokerTable) { table.SetRand(rand.New(rand.NewSource(7))) }},
+	}
+	for _, tCase := range cases {
+		t.Run(tCase.TestCaseName, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+			config := NewTableConfig(10*time.Minute, 10, 2, -1, false)
+			config.LastBlindIncrease = clock.Now()
+			table := NewPokerTable(config, NewTableMeta(50, 0, 1488))
+			tCase.Configure(table)
+			table.SetClock(clock)
+			p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+			p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+			p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+			require.NoError(t, table.AddPlayer(p1))
+			require.NoError(t, table.AddPlayer(p2))
+			require.NoError(t, table.AddPlayer(p3))
+
+			require.NoError(t, table.StartGame())
+			require.NoError(t, table.MakeMove(p2.GetId(), "raise", 300))
+			require.NoError(t, table.MakeMove(p3.GetId(), "fold", 0))
+			require.NoError(t, table.MakeMove(p1.GetId(), "fold", 0))
+			require.Equal(t, 1150, p2.GetBalance())
+
+			require.NoError(t, table.StartGame())
+			playHand(t, table)
+
+			replayed, err := ReplayDebug(table.Debug())
+			require.NoError(t, err)
+			require.False(t, replayed.Meta.GameStarted)
+			require.Equal(t, table.Meta.SmallBlind, replayed.Meta.SmallBlind)
+			require.Equal(t, table.Meta.CommunityCards, replayed.Meta.CommunityCards)
+			for _, p := range []*Player{p1, p2, p3} {
+				require.Equal(t, p.GetBalance(), replayed.Meta.Players[p.GetId()].GetBalance())
+			}
+		})
+	}
+}
+
+func TestReplayRestoresTableState(t *testing.T) {
+	cases := []struct {
+		TestCaseName string
+		BankAmount   int
+		Advance      time.Duration
+	}{
+		{TestCaseName: "Tournament", BankAmount: 1000},
+		// до повышения блайндов осталась минута, повтор не должен поднять их по настоящему времени
+		{TestCaseName: "Injected clock", BankAmount: -1, Advance: 9 * time.Minute},
+	}
+	for _, tCase := range cases {
+		t.Run(tCase.TestCaseName, func(t *testing.T) {
+			clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+			config := NewTableConfig(10*time.Minute, 10, 2, tCase.BankAmount, false)
+			config.LastBlindIncrease = clock.Now()
+			aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+			// внешний тасовщик знает только о двух раздачах, повтор не должен просить у него колоду
+			config.Shuffler = &stackedShuffler{tops: [][]Card{{}, aces}}
+			table := NewPokerTable(config, NewTableMeta(50, 0, 1488))
+			table.SetClock(clock)
+			p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+			p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+			p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+			require.NoError(t, table.AddPlayer(p1))
+			require.NoError(t, table.AddPlayer(p2))
+			require.NoError(t, table.AddPlayer(p3))
+
+			require.NoError(t, table.StartGame())
+			require.NoError(t, table.MakeMove(p2.GetId(), "raise", 300))
+			require.NoError(t, table.MakeMove(p3.GetId(), "fold", 0))
+			require.NoError(t, table.MakeMove(p1.GetId(), "fold", 0))
+
+			clock.Advance(tCase.Advance)
+			seed := table.Meta.Seed
+			moves := []Move{
+				{PlayerId: p3.GetId(), Action: "call"},
+				{PlayerId: p1.GetId(), Action: "call"},
+				{PlayerId: p2.GetId(), Action: "check"},
+			}
+			for range 3 { // флоп, терн и ривер прочекиваются
+				moves = append(moves, Move{PlayerId: p1.GetId(), Action: "check"}, Move{PlayerId: p2.GetId(), Action: "check"}, Move{PlayerId: p3.GetId(), Action: "check"})
+			}
+			require.NoError(t, table.StartGame())
+			require.Equal(t, aces, p1.GetHand().Cards)
+			for _, move := range moves {
+				require.NoError(t, table.MakeMove(move.PlayerId, move.Action, move.Amount))
+			}
+			require.False(t, table.Meta.GameStarted)
+			clock.Advance(time.Hour) // повтор идет позже, время стола уже ушло вперед
+
+			replayed, err := table.Replay(seed, moves)
+			require.NoError(t, err)
+			require.False(t, replayed.Meta.GameStarted)
+			require.Equal(t, aces, replayed.Meta.Players[p1.GetId()].GetHand().Cards)
+			require.Equal(t, table.Meta.SmallBlind, replayed.Meta.SmallBlind)
+			require.Equal(t, table.Meta.CommunityCards, replayed.Meta.CommunityCards)
+			for _, p := range []*Player{p1, p2, p3} {
+				require.Equal(t, p.GetBalance(), replayed.Meta.Players[p.GetId()].GetBalance())
+			}
+		})
+	}
+}
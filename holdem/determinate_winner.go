@@ -2,15 +2,29 @@ package holdem
 
 import (
 	"errors"
+	"slices"
 )
 
 var (
 	ErrEmptyPlayersMap         = errors.New("empty players map")
 	ErrNotEnoughCommunityCards = errors.New("len of community cards must be 5")
 	ErrNotEnoughCardsInHand    = errors.New("len of player cards must be 2") //TODO add
+	ErrAllPlayersFolded        = errors.New("all players folded, nobody can win")
 )
 
+// DeterminateWinner returns every not folded player holding the best hand. Hands are compared
+// by the rank and then by all the cards deciding it, so an equal hand splits the pot.
 func DeterminateWinner(communityCards []Card, players map[string]IPlayer) ([]string, error) {
+	return determinateWinner(communityCards, players, evaluate)
+}
+
+// DeterminateOmahaWinner is DeterminateWinner for Omaha, where every hand is made of exactly
+// two hole cards and three community cards
+func DeterminateOmahaWinner(communityCards []Card, players map[string]IPlayer) ([]string, error) {
+	return determinateWinner(communityCards, players, evaluateOmaha)
+}
+
+func determinateWinner(communityCards []Card, players map[string]IPlayer, eval evaluator) ([]string, error) {
 	if len(players) == 0 {
 		return []string{}, ErrEmptyPlayersMap
 	}
@@ -19,27 +33,48 @@ func DeterminateWinner(communityCards []Card, players map[string]IPlayer) ([]str
 		return []string{}, ErrNotEnoughCommunityCards
 	}
 
-	bestPlayers := make([]string, len(players))
-	var bestCombination Combination
+	bestPlayers := []string{}
+	bestScore := -1
 
 	for id, player := range players {
 		hand := player.GetHand()
 		if player.GetFold() {
 			continue
 		}
-		combination := EvaluateHand(hand.Cards[:], communityCards)
-		if combination.Rank > bestCombination.Rank ||
-			(combination.Rank == bestCombination.Rank && compareCards(combination.CompareCards, bestCombination.CompareCards) > 0) {
+		score := eval(hand.Cards, communityCards).score() // ранг и карты, решающие при равном ранге
+		if score > bestScore {
 			bestPlayers = append(bestPlayers[:0], id)
-			bestCombination = combination
-		} else if combination.Rank == bestCombination.Rank && compareCards(combination.CompareCards, bestCombination.CompareCards) == 0 {
+			bestScore = score
+		} else if score == bestScore {
 			bestPlayers = append(bestPlayers, id)
 		}
 	}
+	if len(bestPlayers) == 0 {
+		return bestPlayers, ErrAllPlayersFolded
+	}
 
 	return bestPlayers, nil
 }
 
+// winningMargin returns the score difference between the winners' combination and the best
+// combination among the other players. It is 0 when nobody lost to the winners.
+func winningMargin(communityCards []Card, players map[string]IPlayer, winners []string, eval evaluator) int {
+	best, runnerUp := -1, -1
+	for id, player := range players {
+		hand := player.GetHand()
+		score := eval(hand.Cards, communityCards).score()
+		if slices.Contains(winners, id) {
+			best = score
+		} else {
+			runnerUp = max(runnerUp, score)
+		}
+	}
+	if best == -1 || runnerUp == -1 {
+		return 0
+	}
+	return best - runnerUp
+}
+
 // Compare two combinations (kickers)
 // return 1 if a > b
 // return -1 if a < b
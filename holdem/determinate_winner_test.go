@@ -139,9 +139,9 @@ func TestDeterminateWinnerGood(t *testing.T) {
 				{Suit: "Hearts", Value: 4}, {Suit: "Clubs", Value: 6},
 			},
 			Players: map[string]IPlayer{
-				"first":  &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 12}}}},
-				"second": &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 14}, {Suit: "Spades", Value: 14}}}},
-				"third":  &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 4}}}},
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 12}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 14}, {Suit: "Spades", Value: 14}}}},
+				"third":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 4}}}},
 			},
 			Expected: []string{"third"},
 		},
@@ -152,8 +152,8 @@ func TestDeterminateWinnerGood(t *testing.T) {
 				{Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 6},
 			},
 			Players: map[string]IPlayer{
-				"first":  &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}},
-				"second": &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 10}}}},
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 10}}}},
 			},
 			Expected:    []string{"first"},
 			ExpectedErr: nil,
@@ -165,8 +165,8 @@ func TestDeterminateWinnerGood(t *testing.T) {
 				{Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 6},
 			},
 			Players: map[string]IPlayer{
-				"first":  &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}, IsFold: true},
-				"second": &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 9}}}},
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}, IsFold: true},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 9}}}},
 			},
 			Expected:    []string{"second"},
 			ExpectedErr: nil,
@@ -178,8 +178,8 @@ func TestDeterminateWinnerGood(t *testing.T) {
 				{Suit: "Hearts", Value: 11}, {Suit: "Clubs", Value: 10},
 			},
 			Players: map[string]IPlayer{
-				"first":  &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}},
-				"second": &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 9}}}},
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 9}}}},
 			},
 			Expected:    []string{"first", "second"},
 			ExpectedErr: nil,
@@ -191,20 +191,81 @@ func TestDeterminateWinnerGood(t *testing.T) {
 				{Suit: "Hearts", Value: 11}, {Suit: "Clubs", Value: 10},
 			},
 			Players: map[string]IPlayer{
-				"first":  &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 5}, {Suit: "Spades", Value: 4}}}},
-				"second": &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 9}}}},
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 5}, {Suit: "Spades", Value: 4}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 9}}}},
 			},
-			Expected:    []string{"first", "second"},
+			Expected:    []string{"second"}, // флеш до туза с десяткой старше флеша до туза с пятеркой
 			ExpectedErr: nil,
 		},
+		{
+			TestCaseName: "Same top pair, different kickers",
+			CommunityCards: []Card{
+				{Suit: "Hearts", Value: 13}, {Suit: "Clubs", Value: 9}, {Suit: "Diamonds", Value: 5},
+				{Suit: "Hearts", Value: 3}, {Suit: "Clubs", Value: 2},
+			},
+			Players: map[string]IPlayer{
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 10}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Diamonds", Value: 13}, {Suit: "Diamonds", Value: 12}}}},
+			},
+			Expected: []string{"second"},
+		},
+		{
+			TestCaseName: "Flush decided by the fourth card",
+			CommunityCards: []Card{
+				{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 11}, {Suit: "Hearts", Value: 8},
+				{Suit: "Clubs", Value: 3}, {Suit: "Spades", Value: 2},
+			},
+			Players: map[string]IPlayer{
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Hearts", Value: 7}, {Suit: "Hearts", Value: 4}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Hearts", Value: 6}, {Suit: "Hearts", Value: 5}}}},
+			},
+			Expected: []string{"first"},
+		},
+		{
+			TestCaseName: "Both play the board",
+			CommunityCards: []Card{
+				{Suit: "Hearts", Value: 14}, {Suit: "Clubs", Value: 14}, {Suit: "Diamonds", Value: 13},
+				{Suit: "Hearts", Value: 13}, {Suit: "Clubs", Value: 12},
+			},
+			Players: map[string]IPlayer{
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 2}, {Suit: "Spades", Value: 3}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Diamonds", Value: 4}, {Suit: "Diamonds", Value: 5}}}},
+				"third":  &Player{Hand: Hand{[]Card{{Suit: "Hearts", Value: 9}, {Suit: "Diamonds", Value: 11}}}},
+			},
+			Expected: []string{"first", "second", "third"},
+		},
+		{
+			TestCaseName: "Full house from two sets of three",
+			CommunityCards: []Card{
+				{Suit: "Hearts", Value: 13}, {Suit: "Clubs", Value: 13}, {Suit: "Hearts", Value: 12},
+				{Suit: "Clubs", Value: 12}, {Suit: "Diamonds", Value: 14},
+			},
+			Players: map[string]IPlayer{
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 12}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Diamonds", Value: 13}, {Suit: "Diamonds", Value: 11}}}},
+			},
+			Expected: []string{"first", "second"}, // у обоих короли на дамах, туз в фулл-хаус не входит
+		},
+		{
+			TestCaseName: "All players folded",
+			CommunityCards: []Card{
+				{Suit: "Hearts", Value: 10}, {Suit: "Clubs", Value: 9}, {Suit: "Diamonds", Value: 8},
+				{Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 6},
+			},
+			Players: map[string]IPlayer{
+				"first": &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}, IsFold: true},
+			},
+			Expected:    []string{},
+			ExpectedErr: ErrAllPlayersFolded,
+		},
 		{
 			TestCaseName: "Not enough community cards",
 			CommunityCards: []Card{
 				{Suit: "Hearts", Value: 10}, {Suit: "Clubs", Value: 9}, {Suit: "Diamonds", Value: 8},
 			},
 			Players: map[string]IPlayer{
-				"first":  &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}},
-				"second": &Player{Hand: Hand{[2]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 9}}}},
+				"first":  &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 12}}}},
+				"second": &Player{Hand: Hand{[]Card{{Suit: "Spades", Value: 10}, {Suit: "Spades", Value: 9}}}},
 			},
 			Expected:    []string{},
 			ExpectedErr: ErrNotEnoughCommunityCards,
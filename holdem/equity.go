@@ -0,0 +1,222 @@
+package holdem
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+const equityIterations = 2000
+
+var (
+	ErrInvalidEquityCards = errors.New("every player needs two hole cards and the board at most five, all cards distinct")
+	ErrInvalidIterations  = errors.New("iterations count must be positive")
+	ErrTooManyRunouts     = errors.New("too many board completions for the exact equity, use Equity")
+)
+
+// maxExactEvaluations is the limit of the hands ExactEquity evaluates over all the board
+// completions: enough for a heads-up preflop, about 1.7 million boards of two hands each
+const maxExactEvaluations = 2 * 1712304
+
+// Equity estimates the share of the pot won by every player by dealing iterations random
+// completions of the board from the cards nobody holds. A tie gives every winner an equal part.
+func Equity(players [][]Card, community []Card, iterations int) ([]float64, error) {
+	if iterations <= 0 {
+		return nil, ErrInvalidIterations
+	}
+	if err := validateEquityCards(players, community); err != nil {
+		return nil, err
+	}
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return monteCarloEquity(players, community, iterations, r, evaluate, GetStandardDeck()), nil
+}
+
+// ExactEquity returns the precise share of the pot won by every player by enumerating all the
+// completions of the board. It covers any hand from the flop and a heads-up one preflop,
+// a bigger enumeration returns ErrTooManyRunouts.
+func ExactEquity(players [][]Card, community []Card) ([]float64, error) {
+	if err := validateEquityCards(players, community); err != nil {
+		return nil, err
+	}
+	known := slices.Clone(community)
+	for _, hand := range players {
+		known = append(known, hand...)
+	}
+	deck := remainingDeck(GetStandardDeck(), known)
+	if runoutsCount(len(deck), 5-len(community))*len(players) > maxExactEvaluations {
+		return nil, ErrTooManyRunouts
+	}
+
+	wins := make([]float64, len(players))
+	scores := make([]int, len(players))
+	total := 0
+	forEachRunout(community, deck, func(board []Card) {
+		total++
+		best, winners := -1, 0
+		for h, hand := range players {
+			scores[h] = scoreSeven(hand, board)
+			if scores[h] > best {
+				best, winners = scores[h], 1
+			} else if scores[h] == best {
+				winners++
+			}
+		}
+		for h := range players {
+			if scores[h] == best {
+				wins[h] += 1 / float64(winners)
+			}
+		}
+	})
+	for h := range wins {
+		wins[h] /= float64(total)
+	}
+	return wins, nil
+}
+
+// runoutsCount returns the number of ways to choose k cards of n
+func runoutsCount(n, k int) int {
+	count := 1
+	for i := 0; i < k; i++ {
+		count = count * (n - i) / (i + 1)
+	}
+	return count
+}
+
+// validateEquityCards checks that there are two hands or more of two cards, at most five community cards
+// and no card is used twice
+func validateEquityCards(players [][]Card, community []Card) error {
+	if len(players) < 2 || len(community) > 5 {
+		return ErrInvalidEquityCards
+	}
+	deck := GetStandardDeck()
+	seen := map[Card]bool{}
+	for _, cards := range append(slices.Clone(players), community) {
+		for _, c := range cards {
+			if seen[c] || !slices.Contains(deck, c) {
+				return ErrInvalidEquityCards
+			}
+			seen[c] = true
+		}
+	}
+	for _, hand := range players {
+		if len(hand) != 2 {
+			return ErrInvalidEquityCards
+		}
+	}
+	return nil
+}
+
+// remainingDeck returns the deck without the known cards
+func remainingDeck(deck []Card, known []Card) []Card {
+	return slices.DeleteFunc(deck, func(c Card) bool {
+		return slices.Contains(known, c)
+	})
+}
+
+// monteCarloEquity estimates the share of the pot won by every hand by dealing random
+// completions of the board. Ties are split between the winners.
+func monteCarloEquity(hands [][]Card, community []Card, iterations int, r *rand.Rand, eval evaluator, fullDeck []Card) []float64 {
+	known := slices.Clone(community)
+	for _, hand := range hands {
+		known = append(known, hand...)
+	}
+	deck := remainingDeck(fullDeck, known)
+	need := 5 - len(community)
+
+	wins := make([]float64, len(hands))
+	board := make([]Card, 5)
+	scores := make([]int, len(hands))
+	for i := 0; i < iterations; i++ {
+		for j := 0; j < need; j++ { // частичное тасование: нужны только первые need карт
+			k := j + r.Intn(len(deck)-j)
+			deck[j], deck[k] = deck[k], deck[j]
+		}
+		copy(board, community)
+		copy(board[len(community):], deck[:need])
+
+		best, winners := -1, 0
+		for h, hand := range hands {
+			scores[h] = eval(hand, board).score()
+			if scores[h] > best {
+				best, winners = scores[h], 1
+			} else if scores[h] == best {
+				winners++
+			}
+		}
+		for h := range hands {
+			if scores[h] == best {
+				wins[h] += 1 / float64(winners)
+			}
+		}
+	}
+	for h := range wins {
+		wins[h] /= float64(iterations)
+	}
+	return wins
+}
+
+// CallEV estimates the expected value of calling the current bet:
+// equity * (pot + call) - call. The equity is computed against random hands of the players still
+// in the hand, dealt from the cards the player can't see: their real hole cards are not used,
+// so the figure is what the player could know at the table.
+func (t *PokerTable) CallEV(playerId string) (float64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.callEV(playerId)
+}
+
+func (t *PokerTable) callEV(playerId string) (float64, error) {
+	if !t.Meta.GameStarted {
+		return 0, ErrGameNotStarted
+	}
+	p, ok := t.Meta.Players[playerId]
+	if !ok {
+		return 0, ErrPlayerNotFound
+	}
+	if p.GetFold() {
+		return 0, ErrPlayerIsFold
+	}
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	opponents := t.playersInHand() - 1
+	equity := randomOpponentsEquity(p.GetHand().Cards, opponents, t.Config.HoleCards, t.Meta.CommunityCards, r, t.evaluator(), t.newDeck())
+	call := min(max(t.Meta.CurrentBet-p.GetLastBet(), 0), p.GetBalance())
+	return equity*float64(t.totalPot()+call) - float64(call), nil
+}
+
+// randomOpponentsEquity estimates the share of the pot won by the hand against opponents whose
+// hole cards are unknown: every iteration deals them random hands of holeCards cards and
+// completes the board from the cards left
+func randomOpponentsEquity(hand []Card, opponents, holeCards int, community []Card, r *rand.Rand, eval evaluator, fullDeck []Card) float64 {
+	deck := remainingDeck(fullDeck, slices.Concat(community, hand))
+	need := 5 - len(community)
+	dealt := need + opponents*holeCards
+
+	wins := 0.0
+	board := make([]Card, 5)
+	for i := 0; i < equityIterations; i++ {
+		for j := 0; j < dealt; j++ { // частичное тасование: нужны только первые dealt карт
+			k := j + r.Intn(len(deck)-j)
+			deck[j], deck[k] = deck[k], deck[j]
+		}
+		copy(board, community)
+		copy(board[len(community):], deck[:need])
+
+		own, winners := eval(hand, board).score(), 1
+		for o := 0; o < opponents; o++ {
+			cards := deck[need+o*holeCards : need+(o+1)*holeCards]
+			score := eval(cards, board).score()
+			if score > own {
+				winners = 0
+				break
+			}
+			if score == own {
+				winners++
+			}
+		}
+		if winners > 0 {
+			wins += 1 / float64(winners)
+		}
+	}
+	return wins / equityIterations
+}
@@ -0,0 +1,120 @@
+package holdem
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonteCarloEquity(t *testing.T) {
+	r := rand.New(rand.NewSource(1488))
+	hands := [][]Card{
+		{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}},
+		{{Suit: "Spades", Value: 7}, {Suit: "Hearts", Value: 2}},
+	}
+	equity := monteCarloEquity(hands, []Card{}, 5000, r, evaluate, GetStandardDeck())
+	require.InDelta(t, 1, equity[0]+equity[1], 1e-9)
+	require.InDelta(t, 0.88, equity[0], 0.03)
+
+	// на ривере исход известен заранее
+	board := []Card{
+		{Suit: "Clubs", Value: 13}, {Suit: "Clubs", Value: 7}, {Suit: "Diamonds", Value: 7},
+		{Suit: "Diamonds", Value: 2}, {Suit: "Clubs", Value: 3},
+	}
+	equity = monteCarloEquity(hands, board, 10, r, evaluate, GetStandardDeck())
+	require.Equal(t, []float64{0, 1}, equity)
+}
+
+func TestCallEV(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	_, err := table.CallEV(p2.GetId())
+	require.Equal(t, ErrGameNotStarted, err)
+	require.NoError(t, table.StartGame())
+
+	// p2 - малый блайнд, доплачивает 50 в банк 150 с парой тузов
+	p2.SetHand(Hand{[]Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}})
+	p1.SetHand(Hand{[]Card{{Suit: "Spades", Value: 7}, {Suit: "Hearts", Value: 2}}})
+	ev, err := table.CallEV(p2.GetId())
+	require.NoError(t, err)
+	require.Greater(t, ev, 0.0)
+
+	// карты соперника игроку не видны: 7-2 против случайной руки выигрывает треть банков,
+	// и колл в банк 150 за 50 выгоден, даже если у соперника на самом деле тузы
+	p2.SetHand(Hand{[]Card{{Suit: "Spades", Value: 7}, {Suit: "Hearts", Value: 2}}})
+	p1.SetHand(Hand{[]Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}})
+	ev, err = table.CallEV(p2.GetId())
+	require.NoError(t, err)
+	require.InDelta(t, 0.35*200-50, ev, 10)
+
+	_, err = table.CallEV("unknown")
+	require.Equal(t, ErrPlayerNotFound, err)
+}
+
+func TestEquity(t *testing.T) {
+	aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+	kings := []Card{{Suit: "Clubs", Value: 13}, {Suit: "Diamonds", Value: 13}}
+	equity, err := Equity([][]Card{aces, kings}, nil, 20000)
+	require.NoError(t, err)
+	require.InDelta(t, 1, equity[0]+equity[1], 1e-9)
+	require.InDelta(t, 0.82, equity[0], 0.02)
+	require.InDelta(t, 0.18, equity[1], 0.02)
+
+	// одинаковые руки почти всегда делят банк
+	equity, err = Equity([][]Card{aces, {{Suit: "Clubs", Value: 14}, {Suit: "Diamonds", Value: 14}}}, nil, 2000)
+	require.NoError(t, err)
+	require.InDelta(t, 0.5, equity[0], 0.02)
+
+	_, err = Equity([][]Card{aces, kings}, nil, 0)
+	require.Equal(t, ErrInvalidIterations, err)
+	_, err = Equity([][]Card{aces, aces}, nil, 100)
+	require.Equal(t, ErrInvalidEquityCards, err)
+	_, err = Equity([][]Card{aces}, nil, 100)
+	require.Equal(t, ErrInvalidEquityCards, err)
+}
+
+func TestExactEquity(t *testing.T) {
+	aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+	flushDraw := []Card{{Suit: "Clubs", Value: 9}, {Suit: "Clubs", Value: 8}}
+
+	// ривер: исход известен
+	river := []Card{
+		{Suit: "Clubs", Value: 13}, {Suit: "Clubs", Value: 7}, {Suit: "Diamonds", Value: 4},
+		{Suit: "Diamonds", Value: 2}, {Suit: "Clubs", Value: 3},
+	}
+	equity, err := ExactEquity([][]Card{aces, flushDraw}, river)
+	require.NoError(t, err)
+	require.Equal(t, []float64{0, 1}, equity)
+
+	// терн: 9 треф из 44 оставшихся карт дают флеш, остальные ничего не меняют
+	turn := river[:4]
+	equity, err = ExactEquity([][]Card{aces, flushDraw}, turn)
+	require.NoError(t, err)
+	require.InDelta(t, 35.0/44, equity[0], 1e-9)
+	require.InDelta(t, 9.0/44, equity[1], 1e-9)
+
+	// флоп: 990 вариантов терна и ривера, доли в сумме дают единицу
+	equity, err = ExactEquity([][]Card{aces, flushDraw}, river[:3])
+	require.NoError(t, err)
+	require.InDelta(t, 1, equity[0]+equity[1], 1e-9)
+	require.Greater(t, equity[1], 9.0/44)
+
+	// префлоп один на один перебираются все 1712304 борда: тузы против королей без общих мастей
+	kings := []Card{{Suit: "Clubs", Value: 13}, {Suit: "Diamonds", Value: 13}}
+	equity, err = ExactEquity([][]Card{aces, kings}, nil)
+	require.NoError(t, err)
+	require.InDelta(t, 0.8126, equity[0], 1e-4)
+	require.InDelta(t, 0.1874, equity[1], 1e-4)
+
+	_, err = ExactEquity([][]Card{aces, kings, flushDraw}, nil)
+	require.Equal(t, ErrTooManyRunouts, err)
+}
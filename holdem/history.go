@@ -0,0 +1,118 @@
+package holdem
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+)
+
+var ErrNoHandRecord = errors.New("no hand is recorded yet")
+
+// HandAction is one action of the hand record, blinds are recorded as "small blind" and "big blind"
+type HandAction struct {
+	Street   Street
+	PlayerId string
+	Action   string
+	Amount   int
+}
+
+// HandRecord describes a finished hand for the analytics. It is built from the table events
+// when TableConfig.RecordHands is set.
+type HandRecord struct {
+	Button     string
+	SmallBlind int
+	Ante       int
+	Stacks     []Seat // стеки на начало раздачи
+	Actions    []HandAction
+	Board      []Card
+	Results    []PotResult
+	Net        map[string]int // выигрыш или проигрыш каждого игрока за раздачу
+	Rake       int
+}
+
+var recordedActions = map[EventKind]string{
+	EventSmallBlind: "small blind",
+	EventBigBlind:   "big blind",
+	EventCheck:      "check",
+	EventBet:        "bet",
+	EventCall:       "call",
+	EventRaise:      "raise",
+	EventAllIn:      "allin",
+	EventFold:       "fold",
+}
+
+// recordEvent adds the event to the record of the current hand
+func (t *PokerTable) recordEvent(e Event) {
+	if !t.Config.RecordHands {
+		return
+	}
+	switch e.Kind {
+	case EventGameStarted:
+		t.record = &HandRecord{
+			SmallBlind: t.Meta.SmallBlind,
+			Ante:       t.Meta.Ante,
+			Stacks:     slices.Clone(t.history.Seats),
+			Actions:    []HandAction{},
+			Board:      []Card{},
+		}
+		return
+	}
+	if t.record == nil {
+		return
+	}
+	switch e.Kind {
+	case EventBlindsIncreased: // блайнды растут уже после старта раздачи
+		t.record.SmallBlind, t.record.Ante = e.Amount, e.Ante
+	case EventDealer:
+		t.record.Button = e.PlayerID
+	case EventCommunityCards:
+		t.record.Board = slices.Clone(e.Cards)
+	case EventHandEnded:
+		t.record.Rake = e.Amount
+		t.record.Results = slices.Clone(t.Meta.ShowdownResults)
+		t.record.Net = make(map[string]int, len(t.record.Stacks))
+		for _, seat := range t.record.Stacks {
+			if p, ok := t.Meta.Players[seat.PlayerId]; ok {
+				t.record.Net[seat.PlayerId] = p.GetBalance() - seat.Balance
+			}
+		}
+		t.records = append(t.records, *t.record)
+		t.record = nil
+	default:
+		if action, ok := recordedActions[e.Kind]; ok {
+			t.record.Actions = append(t.record.Actions, HandAction{
+				Street:   Street(e.Round),
+				PlayerId: e.PlayerID,
+				Action:   action,
+				Amount:   e.Amount,
+			})
+		}
+	}
+}
+
+// HandHistories returns the records of all finished hands, the oldest first
+func (t *PokerTable) HandHistories() []HandRecord {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	records := make([]HandRecord, 0, len(t.records))
+	for _, r := range t.records {
+		r.Stacks = slices.Clone(r.Stacks)
+		r.Actions = slices.Clone(r.Actions)
+		r.Board = slices.Clone(r.Board)
+		r.Results = slices.Clone(r.Results)
+		r.Net = maps.Clone(r.Net)
+		records = append(records, r)
+	}
+	return records
+}
+
+// LastHandJSON serializes the record of the most recent finished hand
+func (t *PokerTable) LastHandJSON() ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.records) == 0 {
+		return nil, ErrNoHandRecord
+	}
+	return json.Marshal(t.records[len(t.records)-1])
+}
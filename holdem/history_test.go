@@ -0,0 +1,75 @@
+package holdem
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandHistories(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.RecordHands = true
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	_, err := table.LastHandJSON()
+	require.Equal(t, ErrNoHandRecord, err)
+
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 200))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "check", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "bet", 100))
+	require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	playHand(t, table)
+
+	records := table.HandHistories()
+	require.Len(t, records, 1)
+	r := records[0]
+	require.Equal(t, p2.GetId(), r.Button)
+	require.Equal(t, 50, r.SmallBlind)
+	require.Equal(t, []Seat{{PlayerId: p1.GetId(), Balance: 1000}, {PlayerId: p2.GetId(), Balance: 1000}, {PlayerId: p3.GetId(), Balance: 1000}}, r.Stacks)
+	require.Equal(t, []HandAction{
+		{PreFlop, p3.GetId(), "small blind", 50},
+		{PreFlop, p1.GetId(), "big blind", 100},
+		{PreFlop, p2.GetId(), "raise", 200},
+		{PreFlop, p3.GetId(), "call", 200},
+		{PreFlop, p1.GetId(), "call", 200},
+		{Flop, p3.GetId(), "check", 0},
+		{Flop, p1.GetId(), "bet", 100},
+		{Flop, p2.GetId(), "fold", 0},
+		{Flop, p3.GetId(), "call", 100},
+		{Turn, p3.GetId(), "check", 0},
+		{Turn, p1.GetId(), "check", 0},
+		{River, p3.GetId(), "check", 0},
+		{River, p1.GetId(), "check", 0},
+	}, r.Actions)
+	require.Len(t, r.Board, 5)
+	require.Equal(t, table.Meta.CommunityCards, r.Board)
+
+	// выплаты совпадают с изменением балансов
+	require.Equal(t, table.Meta.ShowdownResults, r.Results)
+	require.Equal(t, map[string]int{
+		p1.GetId(): p1.GetBalance() - 1000,
+		p2.GetId(): -200,
+		p3.GetId(): p3.GetBalance() - 1000,
+	}, r.Net)
+	require.Equal(t, 0, r.Net[p1.GetId()]+r.Net[p2.GetId()]+r.Net[p3.GetId()])
+
+	data, err := table.LastHandJSON()
+	require.NoError(t, err)
+	var decoded HandRecord
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, r, decoded)
+}
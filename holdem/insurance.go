@@ -0,0 +1,181 @@
+package holdem
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	ErrInsuranceUnavailable = errors.New("insurance is available only for an all-in player before the river")
+	ErrInsuranceTaken       = errors.New("insurance already taken in this hand")
+	ErrInvalidInsurance     = errors.New("insurance amount must be positive and not exceed the pot")
+	ErrNoRunOutPending      = errors.New("the board is not waiting for the run-out")
+)
+
+// Insurance protects an all-in player against losing the hand. The house is the insurer: the premium
+// Amount is taken from the winnings of the player and leaves the table, a losing player gets
+// Amount*Odds paid by the house on top of the chips in play.
+type Insurance struct {
+	Amount int
+	Odds   float64
+}
+
+// forEachRunout calls fn for every completion of the community cards to five cards.
+// The board passed to fn is reused between the calls.
+func forEachRunout(community, deck []Card, fn func(board []Card)) {
+	board := make([]Card, 5)
+	copy(board, community)
+	var deal func(n, from int)
+	deal = func(n, from int) {
+		if n == len(board) {
+			fn(board)
+			return
+		}
+		for i := from; i < len(deck); i++ {
+			board[n] = deck[i]
+			deal(n+1, i+1)
+		}
+	}
+	deal(len(community), 0)
+}
+
+// lossProbability enumerates all runouts and returns the share of them where the hand loses
+// to at least one of the opponents. Split pots are not counted as losses.
+func lossProbability(hand []Card, opponents [][]Card, community []Card, score func(hand, board []Card) int, deck []Card) float64 {
+	known := slices.Concat(community, hand)
+	for _, o := range opponents {
+		known = append(known, o...)
+	}
+	total, losses := 0, 0
+	forEachRunout(community, remainingDeck(deck, known), func(board []Card) {
+		total++
+		own := score(hand, board)
+		for _, o := range opponents {
+			if score(o, board) > own {
+				losses++
+				return
+			}
+		}
+	})
+	if total == 0 {
+		return 0
+	}
+	return float64(losses) / float64(total)
+}
+
+// OfferInsurance returns the fair odds of the insurance for the all-in player:
+// the payout per chip of premium if the player loses the hand.
+// Insurance can be taken before the river while some opponent still has to act. Once nobody
+// can bet the rest of the board is dealt at once, with InsurancePause it waits for RunOut,
+// so the players all-in heads-up or preflop can insure too. Preflop insurance is offered only
+// in the hold'em with the standard deck, other variants would enumerate the boards too long.
+func (t *PokerTable) OfferInsurance(playerId string) (float64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.offerInsurance(playerId)
+}
+
+func (t *PokerTable) offerInsurance(playerId string) (float64, error) {
+	if !t.Meta.GameStarted {
+		return 0, ErrGameNotStarted
+	}
+	p, ok := t.Meta.Players[playerId]
+	if !ok {
+		return 0, ErrPlayerNotFound
+	}
+	if p.GetFold() {
+		return 0, ErrPlayerIsFold
+	}
+	if !p.IsAllIn() || t.Meta.CurrentRound < RoundPreflop || t.Meta.CurrentRound > RoundTurn {
+		return 0, ErrInsuranceUnavailable
+	}
+	standard := !t.Config.ShortDeck && !t.Config.Omaha && t.Config.HoleCards == 2
+	if t.Meta.CurrentRound == RoundPreflop && !standard {
+		return 0, ErrInsuranceUnavailable
+	}
+	score := scoreSeven
+	if !standard {
+		eval := t.evaluator()
+		score = func(hand, board []Card) int { return eval(hand, board).score() }
+	}
+
+	opponents := [][]Card{}
+	for _, k := range t.Meta.PlayersOrder {
+		if k == playerId || t.Meta.Players[k].GetFold() {
+			continue
+		}
+		opponents = append(opponents, t.Meta.Players[k].GetHand().Cards)
+	}
+	loss := lossProbability(p.GetHand().Cards, opponents, t.Meta.CommunityCards, score, t.newDeck())
+	if loss == 0 { // проиграть уже нельзя, страховать нечего
+		return 0, ErrInsuranceUnavailable
+	}
+	return (1 - loss) / loss, nil
+}
+
+// TakeInsurance insures the all-in player for the given amount at the current fair odds.
+// The insurance is settled at the end of the hand.
+func (t *PokerTable) TakeInsurance(playerId string, amount int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	odds, err := t.offerInsurance(playerId)
+	if err != nil {
+		return err
+	}
+	if _, ok := t.Meta.Insurance[playerId]; ok {
+		return ErrInsuranceTaken
+	}
+	if amount <= 0 || amount > t.totalPot() {
+		return ErrInvalidInsurance
+	}
+	t.Meta.Insurance[playerId] = Insurance{Amount: amount, Odds: odds}
+	t.NotifyObservers(Event{Kind: EventInsuranceTaken, PlayerID: playerId, Amount: amount, Odds: odds})
+	return nil
+}
+
+// RunOut deals the rest of the board held by InsurancePause once the players are done with
+// the insurance. Without a call the board is dealt when the move time runs out.
+func (t *PokerTable) RunOut() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.Meta.RunOutPending {
+		return ErrNoRunOutPending
+	}
+	if t.Meta.Paused {
+		return ErrGamePaused
+	}
+	err := t.resumeRunOut()
+	t.skipDisconnected()
+	return err
+}
+
+func (t *PokerTable) resumeRunOut() error {
+	t.Meta.RunOutPending = false
+	t.runningOut = true
+	defer func() { t.runningOut = false }()
+	return t.newRound()
+}
+
+// settleInsurance pays the insured players who won nothing at the showdown
+// and charges the premium from the others
+func (t *PokerTable) settleInsurance() {
+	for k, ins := range t.Meta.Insurance {
+		p, ok := t.Meta.Players[k]
+		if !ok {
+			continue
+		}
+		won := slices.ContainsFunc(t.Meta.ShowdownResults, func(res PotResult) bool {
+			return slices.Contains(res.Winners, k)
+		})
+		if won {
+			premium := min(ins.Amount, p.GetBalance())
+			p.ChangeBalance(-premium)
+			t.NotifyObservers(Event{Kind: EventInsurancePremium, PlayerID: k, Amount: premium})
+			continue
+		}
+		payout := int(float64(ins.Amount) * ins.Odds)
+		p.ChangeBalance(payout)
+		t.NotifyObservers(Event{Kind: EventInsurancePayout, PlayerID: k, Amount: payout})
+	}
+	clear(t.Meta.Insurance)
+}
@@ -0,0 +1,120 @@
+package holdem
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsurance(t *testing.T) {
+	aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+	flushDraw := []Card{{Suit: "Hearts", Value: 9}, {Suit: "Hearts", Value: 8}}
+	junk := []Card{{Suit: "Clubs", Value: 3}, {Suit: "Diamonds", Value: 4}}
+	// флоп, терн и ривер: на ривере у p1 собирается флеш
+	board := []Card{
+		{Suit: "Hearts", Value: 13}, {Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 2},
+		{Suit: "Diamonds", Value: 2}, {Suit: "Hearts", Value: 5},
+	}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.InsurancePause = true
+	config.Shuffler = &stackedShuffler{tops: [][]Card{slices.Concat(flushDraw, junk, aces, board)}}
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	_, err := table.OfferInsurance(p3.GetId())
+	require.Equal(t, ErrGameNotStarted, err)
+	require.NoError(t, table.StartGame())
+	require.Equal(t, aces, p3.GetHand().Cards)
+
+	_, err = table.OfferInsurance(p3.GetId())
+	require.Equal(t, ErrInsuranceUnavailable, err) // не в олл-ине
+
+	// до терна все чекают и коллируют, на терне p3 идет олл-ин, p1 отвечает, p2 сбрасывает
+	for !table.Meta.RunOutPending {
+		current := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+		action := "check"
+		switch {
+		case table.Meta.CurrentRound == RoundTurn && current == p3.GetId():
+			action = "allin"
+		case table.Meta.CurrentBet > table.Meta.Players[current].GetLastBet() && current == p2.GetId() && table.Meta.CurrentRound == RoundTurn:
+			action = "fold"
+		case table.Meta.CurrentBet > table.Meta.Players[current].GetLastBet():
+			action = "call"
+		}
+		require.NoError(t, table.MakeMove(current, action, 0))
+	}
+	require.Equal(t, RoundTurn, table.Meta.CurrentRound)
+	require.Equal(t, board[:4], table.Meta.CommunityCards) // ривер ждет, пока игроки страхуются
+	require.Contains(t, recorder.events, "No more betting possible, the board waits for the insurance")
+
+	// 7 червей из 44 невидимых для p3 карт (2 червей дает тузам фулл-хаус): шанс проиграть 7/44
+	odds, err := table.OfferInsurance(p3.GetId())
+	require.NoError(t, err)
+	require.InDelta(t, 37.0/7, odds, 1e-9)
+
+	require.Equal(t, ErrInvalidInsurance, table.TakeInsurance(p3.GetId(), 0))
+	require.NoError(t, table.TakeInsurance(p3.GetId(), 100))
+	require.Equal(t, ErrInsuranceTaken, table.TakeInsurance(p3.GetId(), 100))
+
+	// p3 проиграл весь стек и получает выплату по страховке
+	require.NoError(t, table.RunOut())
+	require.Equal(t, ErrNoRunOutPending, table.RunOut())
+	require.False(t, table.Meta.GameStarted)
+	require.Equal(t, 528, p3.GetBalance()) // 100 по 37 к 7
+	require.Equal(t, 2100, p1.GetBalance())
+	require.Equal(t, 900, p2.GetBalance())
+	require.Empty(t, table.Meta.Insurance)
+}
+
+func TestInsurancePreflopHeadsUp(t *testing.T) {
+	aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+	kings := []Card{{Suit: "Clubs", Value: 13}, {Suit: "Diamonds", Value: 13}}
+	timer := &fakeTimer{}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.MoveTimeout = 30 * time.Second
+	config.InsurancePause = true
+	config.Shuffler = &stackedShuffler{tops: [][]Card{slices.Concat(aces, kings)}}
+	table := NewPokerTable(config, meta)
+	table.SetTimer(timer)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+
+	first := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	require.NoError(t, table.MakeMove(first, "allin", 0))
+	second := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	require.NoError(t, table.MakeMove(second, "call", 0))
+	require.True(t, table.Meta.RunOutPending)
+	require.Equal(t, RoundPreflop, table.Meta.CurrentRound)
+	require.Empty(t, table.Meta.CommunityCards)
+	require.Equal(t, ErrPlayerAllIn, table.MakeMove(first, "check", 0))
+
+	// короли обыгрывают тузы на 18.6% бордов
+	odds, err := table.OfferInsurance(p1.GetId())
+	require.NoError(t, err)
+	require.InDelta(t, 4.39, odds, 0.01)
+	require.NoError(t, table.TakeInsurance(p1.GetId(), 100))
+
+	// никто не вызвал RunOut: по истечении времени хода борд открывается сам
+	require.Equal(t, 30*time.Second, timer.d)
+	timer.Fire()
+	require.False(t, table.Meta.RunOutPending)
+	require.False(t, table.Meta.GameStarted)
+	require.Len(t, table.Meta.CommunityCards, 5)
+	require.Equal(t, 1900, p1.GetBalance()) // тузы выиграли и отдали премию
+	require.Equal(t, 0, p2.GetBalance())
+}
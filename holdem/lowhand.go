@@ -0,0 +1,76 @@
+package holdem
+
+import (
+	"slices"
+)
+
+// LowHand is a qualifying eight-or-better low: five different values from the highest,
+// the ace counts as one. A smaller LowHand is a better one.
+type LowHand [5]int
+
+const lowQualifier = 8
+
+// LowHandRank returns the best eight-or-better low that can be made of the cards.
+// ok is false when the cards have less than five different values up to eight.
+func LowHandRank(cards []Card) (low LowHand, ok bool) {
+	values := []int{}
+	for _, c := range cards {
+		v := c.Value
+		if v == 14 {
+			v = 1
+		}
+		if v <= lowQualifier && !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+	if len(values) < 5 {
+		return low, false
+	}
+	slices.Sort(values)
+	for i := 0; i < 5; i++ { // пять младших достоинств, от старшего
+		low[i] = values[4-i]
+	}
+	return low, true
+}
+
+// compareLow returns 1 if the low a is better than b, -1 if it is worse and 0 if they are equal
+func compareLow(a, b LowHand) int {
+	return -slices.Compare(a[:], b[:])
+}
+
+// omahaLow returns the best low made of exactly two hole cards and three community cards
+func omahaLow(hole []Card, community []Card) (best LowHand, found bool) {
+	forEachOmahaHand(hole, community, func(two, three []Card) {
+		low, ok := LowHandRank(slices.Concat(two, three))
+		if ok && (!found || compareLow(low, best) > 0) {
+			best, found = low, true
+		}
+	})
+	return best, found
+}
+
+// determinateLowWinners returns the not folded players with the best qualifying low,
+// it is empty when nobody qualifies
+func determinateLowWinners(communityCards []Card, players map[string]IPlayer, omaha bool) []string {
+	winners := []string{}
+	var best LowHand
+	for id, p := range players {
+		if p.GetFold() {
+			continue
+		}
+		low, ok := LowHandRank(slices.Concat(p.GetHand().Cards, communityCards))
+		if omaha {
+			low, ok = omahaLow(p.GetHand().Cards, communityCards)
+		}
+		if !ok {
+			continue
+		}
+		switch {
+		case len(winners) == 0 || compareLow(low, best) > 0:
+			winners, best = []string{id}, low
+		case compareLow(low, best) == 0:
+			winners = append(winners, id)
+		}
+	}
+	return winners
+}
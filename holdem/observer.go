@@ -2,14 +2,322 @@ package holdem
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// EventKind is the type of the table event
+type EventKind int
+
+const (
+	EventPlayerJoined EventKind = iota
+	EventSeatingSet
+	EventGameStarted
+	EventNewRound
+	EventDealer
+	EventAnte
+	EventAnteMissed
+	EventPartialAnte
+	EventSmallBlind
+	EventBigBlind
+	EventHoleCards
+	EventDealtCard
+	EventCommunityCards
+	EventRunOut
+	EventTurn
+	EventNextPlayer
+	EventCheck
+	EventBet
+	EventCall
+	EventRaise
+	EventAllIn
+	EventFold
+	EventWinner
+	EventWinByFold
+	EventPotCapRefund
+	EventBadBeat
+	EventHandEnded
+	EventBlindsIncreased
+	EventSeatReserved
+	EventReservationExpired
+	EventDisconnected
+	EventReconnected
+	EventAutoMove
+	EventTimeBank
+	EventTimeout
+	EventInsuranceTaken
+	EventInsurancePremium
+	EventInsurancePayout
+	EventRebuy
+	EventStateSnapshot
+	EventUncalledBet
+	EventAddOn
+	EventSatOut
+	EventSatIn
+	EventEliminated
+	EventGameOver
+	EventPaused
+	EventResumed
+	EventReveal
+	EventMuck
+	EventRunItTwice
+	EventStraddle
+	EventRunOutPending
+)
+
+// Event is a structured notification about the table. Only the fields meaningful
+// for the Kind are set, String renders the event as a human readable line.
+type Event struct {
+	Kind     EventKind
+	PlayerID string
+	Amount   int
+	Cards    []Card
+	Round    int
+	Index    int      // номер банка или карты при раздаче
+	Players  []string // победители банка или новая рассадка
+	Action   string   // автоматический ход
+	Ante     int
+	Rank     HandRank
+	Odds     float64
+	Duration time.Duration
+	Low      bool              // банк за младшую руку в HiLo
+	Hands    map[string][]Card // у EventWinner: пять карт комбинации каждого победителя, ранг в Rank
+	Pot      int               // у EventTurn: все фишки в банках и ставках улицы
+	MinRaise int               // у EventTurn: до какой суммы можно повысить, от и до
+	MaxRaise int
+	State    *TableStateSnapshot // только у EventStateSnapshot
+}
+
+// Private reports whether the event must reach only the observers of its player. Any event that
+// carries the cards of a player is private, whatever its kind, so a new way to deal cannot leak
+// them. The only exception is Reveal: the cards shown at the showdown are public.
+func (e Event) Private() bool {
+	return e.PlayerID != "" && len(e.Cards) > 0 && e.Kind != EventReveal
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case EventPlayerJoined:
+		return fmt.Sprintf("Player %s enter the game", e.PlayerID)
+	case EventSeatingSet:
+		return fmt.Sprintf("Seating set: %v", e.Players)
+	case EventGameStarted:
+		return "Game started"
+	case EventNewRound:
+		return fmt.Sprintf("New round started. Current round: %d (%s)", e.Round, RoundName(e.Round))
+	case EventDealer:
+		if e.PlayerID == "" {
+			return "dealer button is dead"
+		}
+		return fmt.Sprintf("dealer is %s", e.PlayerID)
+	case EventAnte:
+		return fmt.Sprintf("Get ante: %d", e.Amount)
+	case EventAnteMissed:
+		return fmt.Sprintf("Player %s cant bet ante", e.PlayerID)
+	case EventPartialAnte:
+		return fmt.Sprintf("Player %s is all-in with partial ante %d", e.PlayerID, e.Amount)
+	case EventSmallBlind:
+		return fmt.Sprintf("Player %s bet %d as small blind", e.PlayerID, e.Amount)
+	case EventBigBlind:
+		return fmt.Sprintf("Player %s bet %d as big blind", e.PlayerID, e.Amount)
+	case EventHoleCards:
+		return fmt.Sprintf("Player %s get cards: %v", e.PlayerID, e.Cards)
+	case EventDealtCard:
+		return fmt.Sprintf("DealtCard: player %s card %d", e.PlayerID, e.Index)
+	case EventCommunityCards:
+		return fmt.Sprintf("Community cards: %v", e.Cards)
+	case EventRunOut:
+		return "No more betting possible, dealing the next street"
+	case EventRunOutPending:
+		return "No more betting possible, the board waits for the insurance"
+	case EventTurn:
+		if e.Amount != 0 {
+			return fmt.Sprintf("player %s can do call with %d (pot %d, raise to %d-%d)", e.PlayerID, e.Amount, e.Pot, e.MinRaise, e.MaxRaise)
+		}
+		return fmt.Sprintf("player %s can do check (pot %d, bet %d-%d)", e.PlayerID, e.Pot, e.MinRaise, e.MaxRaise)
+	case EventNextPlayer:
+		return fmt.Sprintf("Next move expect from %s player", e.PlayerID)
+	case EventCheck:
+		return fmt.Sprintf("Player %s do check", e.PlayerID)
+	case EventBet:
+		return fmt.Sprintf("Player %s do bet with %d amount", e.PlayerID, e.Amount)
+	case EventCall:
+		return fmt.Sprintf("Player %s do call with %d amount", e.PlayerID, e.Amount)
+	case EventRaise:
+		return fmt.Sprintf("Player %s do raise with %d amount", e.PlayerID, e.Amount)
+	case EventAllIn:
+		return fmt.Sprintf("Player %s do all-in with %d amount", e.PlayerID, e.Amount)
+	case EventFold:
+		return fmt.Sprintf("Player %s do fold", e.PlayerID)
+	case EventWinner:
+		if e.Low {
+			return fmt.Sprintf("Winners of low pot %.2d with %d amount: %v", e.Index, e.Amount, e.Players)
+		}
+		if e.Rank != 0 {
+			return fmt.Sprintf("Winners of pot %.2d with %d amount: %v with %s", e.Index, e.Amount, e.Players, e.Rank)
+		}
+		return fmt.Sprintf("Winners of pot %.2d with %d amount: %v", e.Index, e.Amount, e.Players)
+	case EventWinByFold:
+		return fmt.Sprintf("Hand ended by fold, player %s wins %d amount", e.PlayerID, e.Amount)
+	case EventPotCapRefund:
+		return fmt.Sprintf("Player %s get %d refund over the pot cap", e.PlayerID, e.Amount)
+	case EventBadBeat:
+		return fmt.Sprintf("BadBeatJackpot: player %s lost with combination %s", e.PlayerID, e.Rank)
+	case EventHandEnded:
+		return fmt.Sprintf("Hand ended, rake %d", e.Amount)
+	case EventBlindsIncreased:
+		return fmt.Sprintf("Blinds increased. Small blind: %d, ante: %d", e.Amount, e.Ante)
+	case EventSeatReserved:
+		return fmt.Sprintf("Seat reserved for player %s for %d hands", e.PlayerID, e.Amount)
+	case EventReservationExpired:
+		return fmt.Sprintf("Seat reservation for player %s expired", e.PlayerID)
+	case EventDisconnected:
+		return fmt.Sprintf("Player %s disconnected", e.PlayerID)
+	case EventReconnected:
+		return fmt.Sprintf("Player %s reconnected", e.PlayerID)
+	case EventAutoMove:
+		return fmt.Sprintf("Player %s is disconnected, auto %s", e.PlayerID, e.Action)
+	case EventTimeBank:
+		return fmt.Sprintf("Player %s uses time bank, %s left", e.PlayerID, e.Duration)
+	case EventTimeout:
+		return fmt.Sprintf("Player %s timed out, auto %s", e.PlayerID, e.Action)
+	case EventInsuranceTaken:
+		return fmt.Sprintf("Player %s take insurance for %d amount with odds %.2f", e.PlayerID, e.Amount, e.Odds)
+	case EventInsurancePremium:
+		return fmt.Sprintf("Player %s pay %d amount for insurance", e.PlayerID, e.Amount)
+	case EventInsurancePayout:
+		return fmt.Sprintf("Player %s get %d amount of insurance", e.PlayerID, e.Amount)
+	case EventRebuy:
+		return fmt.Sprintf("Player %s rebuy for %d amount", e.PlayerID, e.Amount)
+	case EventStateSnapshot:
+		return fmt.Sprintf("State snapshot. Current round: %d", e.Round)
+	case EventAddOn:
+		return fmt.Sprintf("Player %s add-on for %d amount", e.PlayerID, e.Amount)
+	case EventSatOut:
+		return fmt.Sprintf("Player %s sits out", e.PlayerID)
+	case EventSatIn:
+		return fmt.Sprintf("Player %s sits in", e.PlayerID)
+	case EventEliminated:
+		return fmt.Sprintf("Player %s eliminated in place %d", e.PlayerID, e.Index)
+	case EventGameOver:
+		if e.PlayerID == "" {
+			return "Game over, not enough players with chips"
+		}
+		return fmt.Sprintf("Game over, player %s wins", e.PlayerID)
+	case EventPaused:
+		return "Game paused"
+	case EventResumed:
+		return fmt.Sprintf("Game resumed after %s", e.Duration)
+	case EventReveal:
+		return fmt.Sprintf("Player %s shows %v: %s", e.PlayerID, e.Cards, e.Rank)
+	case EventMuck:
+		return fmt.Sprintf("Player %s mucks", e.PlayerID)
+	case EventRunItTwice:
+		return fmt.Sprintf("Board %d: %v", e.Index, e.Cards)
+	case EventStraddle:
+		return fmt.Sprintf("Player %s bet %d as straddle", e.PlayerID, e.Amount)
+	case EventUncalledBet:
+		return fmt.Sprintf("Uncalled bet of %d amount returned to player %s", e.Amount, e.PlayerID)
+	}
+	return fmt.Sprintf("Event %d", e.Kind)
+}
+
 type IObserver interface {
-	Update(event string)
+	Update(event Event)
+}
+
+type playerObserver struct {
+	id       int    // номер подписки, по нему снимается наблюдатель любого типа
+	playerId string // пусто у наблюдателей всего стола
+	obs      IObserver
+}
+
+// OverflowPolicy is what AsyncObserver does when its buffer is full
+type OverflowPolicy int
+
+const (
+	BlockOnFull OverflowPolicy = iota // стол ждет, пока наблюдатель разберет очередь
+	DropOnFull                        // новое событие отбрасывается
+)
+
+// AsyncObserver delivers the events to the wrapped observer from its own goroutine through
+// a bounded buffer, so a slow observer does not hold the table. The events reach the observer
+// in the order they were sent.
+type AsyncObserver struct {
+	obs     IObserver
+	policy  OverflowPolicy
+	events  chan Event
+	quit    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+func NewAsyncObserver(obs IObserver, buffer int, policy OverflowPolicy) *AsyncObserver {
+	a := &AsyncObserver{
+		obs:    obs,
+		policy: policy,
+		events: make(chan Event, buffer),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+func (a *AsyncObserver) run() {
+	defer close(a.done)
+	for {
+		select {
+		case e := <-a.events:
+			a.obs.Update(e)
+		case <-a.quit:
+			for { // отдаем то, что уже в очереди
+				select {
+				case e := <-a.events:
+					a.obs.Update(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *AsyncObserver) Update(event Event) {
+	select {
+	case <-a.quit:
+		return
+	default:
+	}
+	if a.policy == DropOnFull {
+		select {
+		case a.events <- event:
+		default:
+			a.dropped.Add(1)
+		}
+		return
+	}
+	select {
+	case a.events <- event:
+	case <-a.quit:
+	}
+}
+
+// Dropped returns how many events were dropped because the buffer was full
+func (a *AsyncObserver) Dropped() int {
+	return int(a.dropped.Load())
+}
+
+// Close stops accepting events and waits until the queued ones are delivered
+func (a *AsyncObserver) Close() {
+	a.once.Do(func() { close(a.quit) })
+	<-a.done
 }
 
 type Logger struct{}
 
-func (l Logger) Update(event string) {
+func (l Logger) Update(event Event) {
 	fmt.Println("Event:", event)
 }
@@ -0,0 +1,104 @@
+package holdem
+
+// MoveOption describes an action available to a player. Min and Max bound the amount
+// passed to MakeMove, both are 0 for actions without an amount.
+type MoveOption struct {
+	Action string
+	Min    int
+	Max    int
+}
+
+// moveOptions returns the legal actions of the player to act, or nil for anybody else
+func (t *PokerTable) moveOptions(playerId string) []MoveOption {
+	if !t.Meta.GameStarted || t.Meta.PlayersOrder[t.Meta.PlayerTurnInd] != playerId {
+		return nil
+	}
+	p := t.Meta.Players[playerId]
+	if p.GetFold() {
+		return nil
+	}
+
+	options := []MoveOption{{Action: "fold"}}
+	toCall := t.Meta.CurrentBet - p.GetLastBet()
+	if toCall <= 0 {
+		options = append(options, MoveOption{Action: "check"})
+	} else {
+		callAmount := min(toCall, p.GetBalance())
+		options = append(options, MoveOption{Action: "call", Min: callAmount, Max: callAmount})
+	}
+
+	maxAmount := t.maxRaise(playerId)
+	if t.Meta.CurrentBet == 0 {
+		if t.minBet() <= maxAmount {
+			options = append(options, MoveOption{Action: "bet", Min: t.minBet(), Max: maxAmount})
+		}
+	} else {
+		minRaise := t.minRaise()
+		if minRaise <= maxAmount {
+			options = append(options, MoveOption{Action: "raise", Min: minRaise, Max: maxAmount})
+		}
+	}
+	allIn := p.GetBalance() + p.GetLastBet()
+	if p.GetBalance() > toCall && allIn == maxAmount { // если стека не хватает на колл, колл и есть олл-ин
+		options = append(options, MoveOption{Action: "allin", Min: allIn, Max: allIn})
+	}
+	return options
+}
+
+// LegalActions returns the actions the player to act may pass to MakeMove and the bounds
+// of the bet or raise amount. Both bounds are 0 when neither bet nor raise is allowed.
+func (t *PokerTable) LegalActions(playerId string) ([]string, int, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.legalActions(playerId)
+}
+
+func (t *PokerTable) legalActions(playerId string) ([]string, int, int, error) {
+	if !t.Meta.GameStarted {
+		return nil, 0, 0, ErrGameNotStarted
+	}
+	p, ok := t.Meta.Players[playerId]
+	if !ok {
+		return nil, 0, 0, ErrPlayerNotFound
+	}
+	if t.Meta.PlayersOrder[t.Meta.PlayerTurnInd] != playerId {
+		return nil, 0, 0, ErrNotYourTurn
+	}
+	if p.GetFold() {
+		return nil, 0, 0, ErrPlayerIsFold
+	}
+
+	options := t.moveOptions(playerId)
+	actions := make([]string, 0, len(options))
+	minAmount, maxAmount := 0, 0
+	for _, o := range options {
+		actions = append(actions, o.Action)
+		if o.Action == "bet" || o.Action == "raise" {
+			minAmount, maxAmount = o.Min, o.Max
+		}
+	}
+	return actions, minAmount, maxAmount, nil
+}
+
+// AllMoveOptions returns the legal actions of every seated player and the id of the player to act.
+// Only the player to act has options, the others get empty slices.
+func (t *PokerTable) AllMoveOptions() (map[string][]MoveOption, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.allMoveOptions()
+}
+
+func (t *PokerTable) allMoveOptions() (map[string][]MoveOption, string) {
+	options := make(map[string][]MoveOption, len(t.Meta.PlayersOrder))
+	for _, k := range t.Meta.PlayersOrder {
+		options[k] = []MoveOption{}
+	}
+	if !t.Meta.GameStarted {
+		return options, ""
+	}
+	current := t.Meta.PlayersOrder[t.Meta.PlayerTurnInd]
+	if o := t.moveOptions(current); o != nil {
+		options[current] = o
+	}
+	return options, current
+}
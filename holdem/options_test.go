@@ -0,0 +1,123 @@
+package holdem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllMoveOptions(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000} //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000} //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	options, current := table.AllMoveOptions()
+	require.Equal(t, "", current)
+	require.Len(t, options, 3)
+
+	require.NoError(t, table.StartGame())
+	options, current = table.AllMoveOptions()
+	require.Equal(t, p2.GetId(), current)
+	require.Equal(t, []MoveOption{
+		{Action: "fold"},
+		{Action: "call", Min: 100, Max: 100},
+		{Action: "raise", Min: 200, Max: 1000},
+		{Action: "allin", Min: 1000, Max: 1000},
+	}, options[p2.GetId()])
+	require.Empty(t, options[p1.GetId()])
+	require.Empty(t, options[p3.GetId()])
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	options, current = table.AllMoveOptions()
+	require.Equal(t, p1.GetId(), current)
+	require.Equal(t, []MoveOption{
+		{Action: "fold"},
+		{Action: "check"},
+		{Action: "raise", Min: 200, Max: 1000},
+		{Action: "allin", Min: 1000, Max: 1000},
+	}, options[p1.GetId()])
+	require.Empty(t, options[p2.GetId()])
+	require.Empty(t, options[p3.GetId()])
+
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	options, current = table.AllMoveOptions()
+	require.Equal(t, p3.GetId(), current)
+	require.Equal(t, []MoveOption{
+		{Action: "fold"},
+		{Action: "check"},
+		{Action: "bet", Min: 100, Max: 900},
+		{Action: "allin", Min: 900, Max: 900},
+	}, options[p3.GetId()])
+}
+
+func TestBigBlindCanCheckPreflop(t *testing.T) {
+	table := NewPokerTable(NewTableConfig(time.Hour, 10, 2, -1, false), NewTableMeta(50, 0, 1488))
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+
+	// p2 - дилер и малый блайнд
+	require.Equal(t, ErrCantCheck, table.MakeMove(p2.GetId(), "check", 0))
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+}
+
+func TestLegalActions(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000} //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 300}  //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	_, _, _, err := table.LegalActions(p2.GetId())
+	require.Equal(t, ErrGameNotStarted, err)
+	require.NoError(t, table.StartGame())
+
+	_, _, _, err = table.LegalActions(p1.GetId())
+	require.Equal(t, ErrNotYourTurn, err)
+	_, _, _, err = table.LegalActions("unknown")
+	require.Equal(t, ErrPlayerNotFound, err)
+
+	actions, minAmount, maxAmount, err := table.LegalActions(p2.GetId())
+	require.NoError(t, err)
+	require.Equal(t, []string{"fold", "call", "raise", "allin"}, actions)
+	require.Equal(t, 200, minAmount)
+	require.Equal(t, 1000, maxAmount)
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 600))
+
+	// у p3 осталось 250 при ставке 600: рейз недоступен, колл - олл-ин на остаток
+	actions, minAmount, maxAmount, err = table.LegalActions(p3.GetId())
+	require.NoError(t, err)
+	require.Equal(t, []string{"fold", "call"}, actions)
+	require.Equal(t, 0, minAmount)
+	require.Equal(t, 0, maxAmount)
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.True(t, p3.IsAllIn())
+
+	actions, _, _, err = table.LegalActions(p1.GetId())
+	require.NoError(t, err)
+	require.Contains(t, actions, "call")
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+
+	// на флопе ставок нет - можно чекать
+	actions, minAmount, _, err = table.LegalActions(p1.GetId())
+	require.NoError(t, err)
+	require.Equal(t, []string{"fold", "check", "bet", "allin"}, actions)
+	require.Equal(t, 100, minAmount)
+}
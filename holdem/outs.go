@@ -0,0 +1,32 @@
+package holdem
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrOutsStreet = errors.New("outs are counted with two hole cards and three or four community cards")
+
+// Outs returns the cards left in the deck that would improve the hand to a better rank on the next
+// street, grouped by the rank they make. A card that makes the same rank on the board alone, like
+// one pairing the board, is not an out. It works on the flop and on the turn.
+func Outs(hole []Card, community []Card) (map[HandRank][]Card, error) {
+	if len(hole) != 2 || len(community) < 3 || len(community) > 4 {
+		return nil, ErrOutsStreet
+	}
+	known := slices.Concat(hole, community)
+	if err := validateCards(known); err != nil {
+		return nil, err
+	}
+
+	current := evaluate(hole, community).Rank
+	outs := make(map[HandRank][]Card)
+	for _, c := range remainingDeck(GetStandardDeck(), known) {
+		board := append(slices.Clone(community), c)
+		rank := evaluate(hole, board).Rank
+		if rank > current && rank > evaluate(nil, board).Rank { // улучшение должно идти от карманных карт
+			outs[rank] = append(outs[rank], c)
+		}
+	}
+	return outs, nil
+}
@@ -0,0 +1,49 @@
+package holdem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOuts(t *testing.T) {
+	// флеш-дро: девять червей, туз или король дают пару
+	outs, err := Outs(
+		[]Card{{Suit: "Hearts", Value: 14}, {Suit: "Hearts", Value: 13}},
+		[]Card{{Suit: "Hearts", Value: 2}, {Suit: "Hearts", Value: 7}, {Suit: "Clubs", Value: 9}},
+	)
+	require.NoError(t, err)
+	require.Len(t, outs[Flush], 9)
+	for _, c := range outs[Flush] {
+		require.Equal(t, "Hearts", c.Suit)
+	}
+	require.Len(t, outs[OnePair], 6) // шесть тузов и королей, карты в пару к борду руку не улучшают
+	for _, c := range outs[OnePair] {
+		require.Contains(t, []int{13, 14}, c.Value)
+	}
+
+	// карманная пара: карта в пару к борду дает две пары, она считается
+	outs, err = Outs(
+		[]Card{{Suit: "Clubs", Value: 5}, {Suit: "Diamonds", Value: 5}},
+		[]Card{{Suit: "Hearts", Value: 2}, {Suit: "Spades", Value: 7}, {Suit: "Clubs", Value: 9}},
+	)
+	require.NoError(t, err)
+	require.Len(t, outs[TwoPairs], 9)
+	require.Len(t, outs[ThreeOfAKind], 2)
+
+	// двусторонний стрит-дро на терне: четыре пятерки и четыре десятки
+	outs, err = Outs(
+		[]Card{{Suit: "Clubs", Value: 8}, {Suit: "Diamonds", Value: 9}},
+		[]Card{{Suit: "Hearts", Value: 7}, {Suit: "Spades", Value: 6}, {Suit: "Clubs", Value: 2}, {Suit: "Diamonds", Value: 13}},
+	)
+	require.NoError(t, err)
+	require.Len(t, outs[Straight], 8)
+	for _, c := range outs[Straight] {
+		require.Contains(t, []int{5, 10}, c.Value)
+	}
+
+	_, err = Outs([]Card{{Suit: "Clubs", Value: 8}, {Suit: "Diamonds", Value: 9}}, []Card{{Suit: "Hearts", Value: 7}})
+	require.Equal(t, ErrOutsStreet, err)
+	_, err = Outs([]Card{{Suit: "Clubs", Value: 8}, {Suit: "Clubs", Value: 8}}, []Card{{Suit: "Hearts", Value: 7}, {Suit: "Spades", Value: 6}, {Suit: "Clubs", Value: 2}})
+	require.Equal(t, ErrInvalidCards, err)
+}
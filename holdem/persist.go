@@ -0,0 +1,120 @@
+package holdem
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// savedTable is the wire form of the table. IPlayer can't be decoded from JSON,
+// so the players are stored separately as savedPlayer values.
+type savedTable struct {
+	Config  TableConfig
+	Meta    TableMeta
+	Players map[string]*savedPlayer
+	Query   map[string]*savedPlayer
+	History HandHistory
+	// MoveTimeLeft is the time the player to act had left at Save, nil if the move timer was not running
+	MoveTimeLeft *time.Duration
+}
+
+// savedPlayer is the wire form of a player. The id is kept as a string, so a player of any
+// IPlayer type can be saved, not only the one whose id is a UUID.
+type savedPlayer struct {
+	Balance int
+	Id      string
+	Status  bool
+	LastBet int
+	Hand    Hand
+	IsFold  bool
+	AllIn   bool
+}
+
+func toSaved(p IPlayer) *savedPlayer {
+	return &savedPlayer{
+		Balance: p.GetBalance(),
+		Id:      p.GetId(),
+		Status:  p.GetReadyStatus(),
+		LastBet: p.GetLastBet(),
+		Hand:    p.Copy().GetHand(),
+		IsFold:  p.GetFold(),
+		AllIn:   p.IsAllIn(),
+	}
+}
+
+func toSavedPlayers(players map[string]IPlayer) map[string]*savedPlayer {
+	out := make(map[string]*savedPlayer, len(players))
+	for k, v := range players {
+		out[k] = toSaved(v)
+	}
+	return out
+}
+
+// player restores the player: a *Player if the id is a canonical UUID, a namedPlayer otherwise
+func (s *savedPlayer) player() IPlayer {
+	p := Player{Balance: s.Balance, Status: s.Status, LastBet: s.LastBet, Hand: s.Hand, IsFold: s.IsFold, AllIn: s.AllIn}
+	if id, err := uuid.Parse(s.Id); err == nil && id.String() == s.Id {
+		p.Id = id
+		return &p
+	}
+	return &namedPlayer{Player: p, name: s.Id}
+}
+
+func fromSavedPlayers(players map[string]*savedPlayer) map[string]IPlayer {
+	out := make(map[string]IPlayer, len(players))
+	for k, v := range players {
+		out[k] = v.player()
+	}
+	return out
+}
+
+// Save serializes the config, the meta and the history of the current hand. Observers, the
+// Shuffler and the SwapShuffler are not saved. The move timer is saved as the time left to the
+// player to act, the time bank in use is saved with the time already spent taken off.
+func (t *PokerTable) Save() ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	saved := savedTable{
+		Config:  *t.Config,
+		Meta:    *t.Meta.clone(),
+		Players: toSavedPlayers(t.Meta.Players),
+		Query:   toSavedPlayers(t.Meta.Query),
+		History: t.history,
+	}
+	saved.Meta.Players, saved.Meta.Query = nil, nil
+	if !t.bankStart.IsZero() {
+		saved.Meta.TimeBanks[t.bankUser] = max(saved.Meta.TimeBanks[t.bankUser]-t.now().Sub(t.bankStart), 0)
+		left := time.Duration(0) // время на ход уже вышло, после Load сразу включится остаток банка
+		saved.MoveTimeLeft = &left
+	}
+	if !t.moveDeadline.IsZero() {
+		left := max(t.moveDeadline.Sub(t.now()), 0)
+		saved.MoveTimeLeft = &left
+	}
+	return json.Marshal(saved)
+}
+
+// Load replaces the state of the table with the one produced by Save. The observers of the table
+// are kept, the player to act gets only the move time left at Save, a full MoveTimeout if the
+// timer was not running.
+func (t *PokerTable) Load(data []byte) error {
+	var saved savedTable
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	saved.Meta.Players = fromSavedPlayers(saved.Players)
+	saved.Meta.Query = fromSavedPlayers(saved.Query)
+	t.Config = &saved.Config
+	t.Meta = &saved.Meta
+	t.history = saved.History
+	t.bankUser, t.bankStart = "", time.Time{}
+	if saved.MoveTimeLeft != nil {
+		t.restartMoveTimer(*saved.MoveTimeLeft)
+	} else {
+		t.startMoveTimer()
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package holdem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	newTable := func() *PokerTable {
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		config.LastBlindIncrease = clock.Now()
+		config.Deterministic = true // копии должны выбрать один и тот же сид следующей раздачи
+		table := NewPokerTable(config, NewTableMeta(50, 0, 1488))
+		table.SetClock(clock)
+		return table
+	}
+	table := newTable()
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "bet", 100)) // флоп, ставка еще не уравнена
+
+	data, err := table.Save()
+	require.NoError(t, err)
+	restored := newTable()
+	recorder := &eventRecorder{}
+	restored.AddObserver(recorder)
+	require.NoError(t, restored.Load(data))
+	require.Equal(t, table.Config, restored.Config)
+	require.Equal(t, table.Meta, restored.Meta)
+	require.Equal(t, table.Debug(), restored.Debug())
+	require.Len(t, recorder.events, 1) // только начальный снимок, Load ничего не рассылает
+
+	// обе копии доигрываются одинаково
+	for _, tbl := range []*PokerTable{table, restored} {
+		require.NoError(t, tbl.MakeMove(p1.GetId(), "call", 0))
+		require.NoError(t, tbl.MakeMove(p2.GetId(), "fold", 0))
+		playHand(t, tbl)
+	}
+	require.Equal(t, table.Meta, restored.Meta)
+	require.NotEmpty(t, recorder.events) // наблюдатели стола остались подписаны
+
+	require.Error(t, restored.Load([]byte("{")))
+}
+
+func TestSaveLoadMoveTimer(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	newTable := func(timer *fakeTimer) *PokerTable {
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		config.LastBlindIncrease = clock.Now()
+		config.MoveTimeout = 30 * time.Second
+		config.TimeBank = time.Minute
+		table := NewPokerTable(config, NewTableMeta(50, 0, 1488))
+		table.SetClock(clock)
+		table.SetTimer(timer)
+		return table
+	}
+	timer := &fakeTimer{}
+	table := newTable(timer)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	// p2 думал 20 секунд, после загрузки у него остается 10
+	clock.Advance(20 * time.Second)
+	data, err := table.Save()
+	require.NoError(t, err)
+	restoredTimer := &fakeTimer{}
+	restored := newTable(restoredTimer)
+	require.NoError(t, restored.Load(data))
+	require.Equal(t, 10*time.Second, restoredTimer.d)
+
+	// время на ход вышло и идет банк: после загрузки сразу включается остаток банка
+	timer.Fire()
+	require.Equal(t, time.Minute, timer.d)
+	clock.Advance(15 * time.Second)
+	data, err = table.Save()
+	require.NoError(t, err)
+	restoredTimer = &fakeTimer{}
+	restored = newTable(restoredTimer)
+	require.NoError(t, restored.Load(data))
+	require.Zero(t, restoredTimer.d)
+	restoredTimer.Fire()
+	require.Equal(t, 45*time.Second, restoredTimer.d)
+	restoredTimer.Fire()
+	require.True(t, restored.Meta.Players[p2.GetId()].GetFold())
+}
+
+// loginPlayer is an IPlayer of the caller whose id is a login, not a UUID
+type loginPlayer struct {
+	*Player
+	login string
+}
+
+func (p *loginPlayer) GetId() string {
+	return p.login
+}
+
+func TestSaveLoadAnyPlayerId(t *testing.T) {
+	table := NewPokerTable(NewTableConfig(time.Hour, 10, 2, -1, false), NewTableMeta(50, 0, 1488))
+	alice := &loginPlayer{Player: &Player{Balance: 1000}, login: "alice"}
+	bob := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(alice))
+	require.NoError(t, table.AddPlayer(bob))
+	require.NoError(t, table.StartGame())
+	moves := []Move{{PlayerId: table.Meta.PlayersOrder[table.Meta.PlayerTurnInd], Action: "call"}}
+	require.NoError(t, table.MakeMove(moves[0].PlayerId, moves[0].Action, moves[0].Amount))
+
+	data, err := table.Save()
+	require.NoError(t, err)
+	restored := NewPokerTable(NewTableConfig(time.Hour, 10, 2, -1, false), NewTableMeta(50, 0, 1488))
+	require.NoError(t, restored.Load(data))
+	require.Equal(t, "alice", restored.Meta.Players["alice"].GetId())
+	require.Equal(t, alice.GetBalance(), restored.Meta.Players["alice"].GetBalance())
+	require.Equal(t, alice.GetHand(), restored.Meta.Players["alice"].GetHand())
+	require.IsType(t, &Player{}, restored.Meta.Players[bob.GetId()]) // UUID по-прежнему поднимается как *Player
+	require.Equal(t, table.GetState(), restored.GetState())
+
+	replayed, err := table.Replay(table.Meta.Seed, moves)
+	require.NoError(t, err)
+	require.Equal(t, table.GetState(), replayed.GetState())
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 
 	"github.com/google/uuid"
 )
@@ -24,11 +25,14 @@ type IPlayer interface {
 	SetHand(h Hand)
 	GetLastBet() int
 	SetLastBet(bet int)
+	IsAllIn() bool
+	SetAllIn(status bool)
+	Copy() IPlayer
 	fmt.Stringer
 }
 
 type Hand struct {
-	Cards [2]Card
+	Cards []Card
 }
 
 type Player struct {
@@ -38,12 +42,17 @@ type Player struct {
 	LastBet int
 	Hand    Hand
 	IsFold  bool
+	AllIn   bool
 }
 
 func (p *Player) String() string {
+	return p.describe(p.Id.String())
+}
+
+func (p *Player) describe(id string) string {
 	return fmt.Sprintf(
-		"Player %s:\n balance = %d\n ready status = %v\n last bet = %d\n card in hands: %v\n fold his cards = %v",
-		p.Id.String(), p.Balance, p.Status, p.LastBet, p.Hand, p.IsFold,
+		"Player %s:\n balance = %d\n ready status = %v\n last bet = %d\n card in hands: %v\n fold his cards = %v\n all-in = %v",
+		id, p.Balance, p.Status, p.LastBet, p.Hand, p.IsFold, p.AllIn,
 	)
 }
 
@@ -103,3 +112,37 @@ func (p *Player) GetFold() bool {
 func (p *Player) SetFold(status bool) {
 	p.IsFold = status
 }
+
+func (p *Player) IsAllIn() bool {
+	return p.AllIn
+}
+
+func (p *Player) SetAllIn(status bool) {
+	p.AllIn = status
+}
+
+func (p *Player) Copy() IPlayer {
+	c := *p
+	c.Hand.Cards = slices.Clone(p.Hand.Cards)
+	return &c
+}
+
+// namedPlayer is a player restored by Load or a replay whose id is not a UUID
+type namedPlayer struct {
+	Player
+	name string
+}
+
+func (p *namedPlayer) GetId() string {
+	return p.name
+}
+
+func (p *namedPlayer) String() string {
+	return p.describe(p.name)
+}
+
+func (p *namedPlayer) Copy() IPlayer {
+	c := *p
+	c.Hand.Cards = slices.Clone(p.Hand.Cards)
+	return &c
+}
@@ -1,12 +1,40 @@
 package holdem
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+)
 
 type Pot struct {
 	Amount     int
 	Applicants []string
 }
 
+type PotResult struct {
+	Amount  int
+	Winners []string
+	Margin  int  // на сколько комбинация победителя сильнее лучшей проигравшей, 0 при дележе банка
+	Low     bool // половина банка за младшую руку в HiLo
+}
+
+// PotInfo describes a pot for the clients: its amount, the players who can win it
+// and the label "main" or "side N"
+type PotInfo struct {
+	Amount     int
+	Applicants []string
+	Label      string
+}
+
+// PotContribution is the part of a call that goes into one pot layer of the current street
+type PotContribution struct {
+	Amount     int
+	Applicants []string
+}
+
+// CreatePots layers the bets of the street into the main pot and the side pots above it. Every
+// layer ends at the smallest bet of a player still in the hand, so an all-in player is an applicant
+// only of the pots the player contributed to. The chips of folded players go to the pots without making
+// them applicants.
 func CreatePots(players map[string]IPlayer) []Pot {
 	pots := []Pot{}
 
@@ -24,15 +52,29 @@ func CreatePots(players map[string]IPlayer) []Pot {
 			minBet = min(v.GetLastBet(), minBet)
 		}
 
-		for _, k := range applicants {
-			v := players[k]
-			players[k].SetLastBet(v.GetLastBet() - minBet)
-		}
-
 		if minBet == -1 {
 			break
 		}
-		pots = append(pots, Pot{Amount: len(applicants) * minBet, Applicants: applicants})
+
+		amount := 0
+		for _, v := range players {
+			contribution := min(v.GetLastBet(), minBet) // сбросившие тоже платят в уровень
+			amount += contribution
+			v.SetLastBet(v.GetLastBet() - contribution)
+		}
+		slices.Sort(applicants)
+		pots = append(pots, Pot{Amount: amount, Applicants: applicants})
+	}
+
+	dead := 0 // ставки сбросивших выше последнего уровня
+	for _, v := range players {
+		dead += v.GetLastBet()
+		v.SetLastBet(0)
+	}
+	if dead > 0 && len(pots) > 0 {
+		pots[len(pots)-1].Amount += dead
+	} else if dead > 0 {
+		pots = append(pots, Pot{Amount: dead})
 	}
 	return pots
 }
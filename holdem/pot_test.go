@@ -54,7 +54,7 @@ func TestPot(t *testing.T) {
 				},
 			},
 			Expected: []Pot{
-				Pot{Amount: 1000, Applicants: []string{"1", "2"}},
+				Pot{Amount: 1500, Applicants: []string{"1", "2"}},
 			},
 		},
 		{
@@ -113,8 +113,33 @@ func TestPot(t *testing.T) {
 				},
 			},
 			Expected: []Pot{
-				Pot{Amount: 600, Applicants: []string{"2", "3"}},
-				Pot{Amount: 100, Applicants: []string{"2"}},
+				Pot{Amount: 900, Applicants: []string{"2", "3"}},
+				Pot{Amount: 300, Applicants: []string{"2"}},
+			},
+		},
+		{
+			TestCaseName: "three all-ins",
+			Data: map[string]IPlayer{
+				"1": &Player{
+					LastBet: 50,
+					AllIn:   true,
+				},
+				"2": &Player{
+					LastBet: 100,
+					AllIn:   true,
+				},
+				"3": &Player{
+					LastBet: 200,
+					AllIn:   true,
+				},
+				"4": &Player{
+					LastBet: 200,
+				},
+			},
+			Expected: []Pot{
+				Pot{Amount: 200, Applicants: []string{"1", "2", "3", "4"}},
+				Pot{Amount: 150, Applicants: []string{"2", "3", "4"}},
+				Pot{Amount: 200, Applicants: []string{"3", "4"}},
 			},
 		},
 	}
@@ -123,6 +148,7 @@ func TestPot(t *testing.T) {
 		t.Run(tCase.TestCaseName,
 			func(t *testing.T) {
 				res := CreatePots(tCase.Data)
+				require.Len(t, res, len(tCase.Expected))
 				for k, _ := range res {
 					require.ElementsMatch(t, res[k].Applicants, tCase.Expected[k].Applicants)
 					require.Equal(t, res[k].Amount, tCase.Expected[k].Amount)
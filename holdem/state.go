@@ -0,0 +1,60 @@
+package holdem
+
+import "slices"
+
+// PlayerState is the public part of a seated player
+type PlayerState struct {
+	Id      string
+	Balance int
+	LastBet int
+	Fold    bool
+	AllIn   bool
+}
+
+// TableStateSnapshot is a copy of the public table state, changing it does not affect the table
+type TableStateSnapshot struct {
+	GameStarted    bool
+	Round          int
+	CurrentBet     int
+	Turn           string // игрок, от которого ждут хода, пусто вне раздачи
+	CommunityCards []Card
+	Pots           []int // собранные банки прошлых улиц
+	TotalPot       int   // вместе со ставками текущей улицы
+	Players        []PlayerState
+}
+
+// GetState returns a deep copy of the public table state. Hole cards are not included.
+func (t *PokerTable) GetState() TableStateSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.getState()
+}
+
+func (t *PokerTable) getState() TableStateSnapshot {
+	state := TableStateSnapshot{
+		GameStarted:    t.Meta.GameStarted,
+		Round:          t.Meta.CurrentRound,
+		CurrentBet:     t.Meta.CurrentBet,
+		CommunityCards: slices.Clone(t.Meta.CommunityCards),
+		Pots:           make([]int, 0, len(t.Meta.Pots)),
+		TotalPot:       t.totalPot(),
+		Players:        make([]PlayerState, 0, len(t.Meta.PlayersOrder)),
+	}
+	if t.Meta.GameStarted {
+		state.Turn = t.Meta.PlayersOrder[t.Meta.PlayerTurnInd]
+	}
+	for _, pot := range t.Meta.Pots {
+		state.Pots = append(state.Pots, pot.Amount)
+	}
+	for _, k := range t.Meta.PlayersOrder {
+		p := t.Meta.Players[k]
+		state.Players = append(state.Players, PlayerState{
+			Id:      k,
+			Balance: p.GetBalance(),
+			LastBet: p.GetLastBet(),
+			Fold:    p.GetFold(),
+			AllIn:   p.IsAllIn(),
+		})
+	}
+	return state
+}
@@ -0,0 +1,78 @@
+package holdem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetState(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	state := table.GetState()
+	require.False(t, state.GameStarted)
+	require.Equal(t, "", state.Turn)
+	require.Len(t, state.Players, 3)
+
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "fold", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "bet", 100))
+
+	state = table.GetState()
+	require.True(t, state.GameStarted)
+	require.Equal(t, int(Flop), state.Round)
+	require.Equal(t, 100, state.CurrentBet)
+	require.Equal(t, p2.GetId(), state.Turn)
+	require.Equal(t, []int{300}, state.Pots)
+	require.Equal(t, 400, state.TotalPot)
+	require.Equal(t, table.Meta.CommunityCards, state.CommunityCards)
+	require.Equal(t, []PlayerState{
+		{Id: p1.GetId(), Balance: 800, LastBet: 100},
+		{Id: p2.GetId(), Balance: 900},
+		{Id: p3.GetId(), Balance: 900, Fold: true},
+	}, state.Players)
+
+	// снимок не связан с состоянием стола
+	state.CommunityCards[0] = Card{Suit: "Hearts", Value: 2}
+	state.Players[0].Balance = 0
+	require.NotEqual(t, state.CommunityCards, table.Meta.CommunityCards)
+	require.Equal(t, 800, p1.GetBalance())
+}
+
+func TestSnapshotOnSubscribe(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+
+	// подписались посреди раздачи
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	require.Len(t, recorder.raw, 1)
+	first := recorder.raw[0]
+	require.Equal(t, EventStateSnapshot, first.Kind)
+	require.Equal(t, table.GetState(), *first.State)
+	require.Equal(t, p1.GetId(), first.State.Turn)
+
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.Greater(t, len(recorder.raw), 1)
+	require.Equal(t, Event{Kind: EventCheck, PlayerID: p1.GetId()}, recorder.raw[1])
+}
@@ -1,28 +1,118 @@
 package holdem
 
 import (
+	"cmp"
+	crand "crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"math/rand"
+	"reflect"
 	"slices"
 	"sync"
 	"time"
 )
 
 var (
-	ErrMaxPlayers       = errors.New("count of players reached max value")
-	ErrGameStarted      = errors.New("this game already started")
-	ErrGameNotStarted   = errors.New("this game not started")
-	ErrNotEnoughCards   = errors.New("not enough card in deck")
-	ErrNotYourTurn      = errors.New("not your turn t")
-	ErrPlayerIsFold     = errors.New("player already fold his cards")
-	ErrCantCheck        = errors.New("you cant check")
-	ErrCantRaise        = errors.New("raise must exceed the current bet by at least two times")
-	ErrNotEnoughMoney   = errors.New("not enough money for this  action")
-	ErrUnexpectedAction = errors.New("unexpected action")
-	ErrPlayerNotFound   = errors.New("player not found")
+	ErrMaxPlayers         = errors.New("count of players reached max value")
+	ErrGameStarted        = errors.New("this game already started")
+	ErrGameNotStarted     = errors.New("this game not started")
+	ErrNotEnoughCards     = errors.New("not enough card in deck")
+	ErrNotYourTurn        = errors.New("not your turn t")
+	ErrPlayerIsFold       = errors.New("player already fold his cards")
+	ErrCantCheck          = errors.New("you cant check")
+	ErrRaiseTooSmall      = errors.New("raise is less than the minimum raise")
+	ErrRaiseTooBig        = errors.New("raise exceeds the betting limit")
+	ErrRaiseCapReached    = errors.New("raise cap for the street is reached")
+	ErrTargetCovered      = errors.New("the current bet already covers the stack of the target")
+	ErrNotEnoughMoney     = errors.New("not enough money for this  action")
+	ErrUnexpectedAction   = errors.New("unexpected action")
+	ErrPlayerNotFound     = errors.New("player not found")
+	ErrInvalidHands       = errors.New("hands count must be positive")
+	ErrBetTooSmall        = errors.New("bet is less than the minimum bet")
+	ErrCantBet            = errors.New("you cant bet, the bet has already been made")
+	ErrInvalidHoleCards   = errors.New("hole cards count must not be negative")
+	ErrOmahaHoleCards     = errors.New("omaha needs at least two hole cards")
+	ErrInvalidSeating     = errors.New("seating must list every seated player exactly once")
+	ErrBoardComplete      = errors.New("all community cards are already dealt")
+	ErrPlayerAllIn        = errors.New("player is all-in and has no more actions")
+	ErrRebuyClosed        = errors.New("rebuy period is over")
+	ErrAnteNotAllowed     = errors.New("ante is posted once at the start of the preflop")
+	ErrDuplicatePlayer    = errors.New("player with this id is already at the table")
+	ErrInvalidRebuy       = errors.New("rebuy amount must be positive")
+	ErrRebuyLimit         = errors.New("player reached the rebuy limit")
+	ErrAddOnTaken         = errors.New("add-on is available once per player")
+	ErrNotEnoughPlayers   = errors.New("at least two players must be in the hand")
+	ErrBuyInTooSmall      = errors.New("buy-in is below the table minimum")
+	ErrBuyInTooBig        = errors.New("buy-in is above the table maximum")
+	ErrGameOver           = errors.New("game is over")
+	ErrGamePaused         = errors.New("game is paused")
+	ErrGameNotPaused      = errors.New("game is not paused")
+	ErrStraddleNotAllowed = errors.New("straddle is posted by the player left of the big blind before the preflop action")
+	ErrInvalidStraddle    = errors.New("straddle must be double the big blind")
+	ErrInvalidMaxPlayers  = errors.New("max players must be at least two")
+	ErrInvalidMinPlayers  = errors.New("min players must not be negative or exceed max players")
+	ErrInvalidBlind       = errors.New("small blind must not be negative")
+	ErrInvalidAnte        = errors.New("ante must not be negative")
 )
 
+type Street int
+
+const (
+	PreFlop Street = iota
+	Flop
+	Turn
+	River
+)
+
+// Values of TableMeta.CurrentRound. The betting rounds match the Street of the same name.
+const (
+	RoundPreDeal = iota - 1 // раздача еще не началась
+	RoundPreflop
+	RoundFlop
+	RoundTurn
+	RoundRiver
+	RoundShowdown
+)
+
+var roundNames = map[int]string{
+	RoundPreDeal:  "predeal",
+	RoundPreflop:  "preflop",
+	RoundFlop:     "flop",
+	RoundTurn:     "turn",
+	RoundRiver:    "river",
+	RoundShowdown: "showdown",
+}
+
+// RoundName returns the readable name of the CurrentRound value
+func RoundName(round int) string {
+	if name, ok := roundNames[round]; ok {
+		return name
+	}
+	return fmt.Sprintf("round %d", round)
+}
+
+// LimitType is the betting structure of the table
+type LimitType int
+
+const (
+	NoLimit LimitType = iota
+	PotLimit
+	FixedLimit
+)
+
+// AnteType is who posts the ante
+type AnteType int
+
+const (
+	ClassicAnte  AnteType = iota // каждый игрок ставит анте
+	BigBlindAnte                 // большой блайнд ставит одно анте за весь стол
+)
+
+const defaultRaiseCap = 4
+
 type IPokerTable interface {
 	StartGame()
 	AddObserver(o *IObserver)
@@ -31,38 +121,150 @@ type IPokerTable interface {
 	MakeMove(playerId, action string, amount int)
 }
 
+// BlindLevel is one step of the blind schedule
+type BlindLevel struct {
+	SmallBlind int
+	Ante       int
+}
+
+// Clock tells the current time, the table uses time.Now unless another clock is set
+type Clock interface {
+	Now() time.Time
+}
+
+// Timer runs f after the duration d and returns the function cancelling it.
+// The table uses time.AfterFunc unless another timer is set.
+type Timer interface {
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+type systemTimer struct{}
+
+func (systemTimer) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
 type TableConfig struct {
-	BlindIncreaseTime time.Duration
-	LastBlindIncrease time.Time
-	MaxPlayers        int
-	MinPlayers        int
-	EnterAfterStart   bool
-	BankAmount        int
+	BlindIncreaseTime  time.Duration
+	LastBlindIncrease  time.Time
+	BlindLevels        []BlindLevel // уровни блайндов по порядку, пустой список - удвоение
+	MaxPlayers         int
+	MinPlayers         int
+	MoveTimeout        time.Duration // время на ход, по истечении авто чек или фолд; 0 - без ограничения
+	TimeBank           time.Duration // запас времени сверх MoveTimeout, он же максимум банка
+	TimeBankRefill     time.Duration // сколько возвращается в банк в начале каждой раздачи
+	EnterAfterStart    bool
+	BankAmount         int           // стартовый стек турнира, 0 и меньше - кэш-игра со своими балансами
+	RebuyPeriod        time.Duration // сколько после старта можно докупаться, 0 - без ребаев
+	MaxRebuys          int           // ребаев на игрока, 0 - без ограничения
+	AllowAddOn         bool          // разрешить один add-on на игрока в период ребаев
+	MinBuyIn           int           // границы стека при входе в кэш-игру и суммы ребая, 0 - без ограничения
+	MaxBuyIn           int
+	LimitType          LimitType
+	SmallBet           int  // шаг ставки фикс-лимита на префлопе и флопе, по умолчанию большой блайнд
+	BigBet             int  // шаг на терне и ривере, по умолчанию два SmallBet
+	RaiseCap           int  // рейзов на улице в фикс-лимите, по умолчанию 4
+	HoleCards          int  // карт на руках у каждого игрока, 0 - все играют только борд
+	BurnCards          bool // сжигать карту перед флопом, терном и ривером
+	BurnCount          int  // сколько карт сжигать перед каждой улицей, 0 - одна при BurnCards
+	GranularDealEvents bool // раздавать карманные карты по одной с событием DealtCard на каждую
+	ShortDeck          bool // колода из 36 карт от шестерки, флеш старше фулл-хауса
+	Omaha              bool // рука составляется ровно из двух карманных и трех общих карт
+	HiLo               bool // каждый банк делится пополам с лучшей младшей рукой восемь или ниже
+	RecordHands        bool // сохранять HandRecord каждой сыгранной раздачи
+	MuckLosers         bool // на вскрытии проигравшие руки сбрасываются не открываясь
+	RunItTwice         bool // при олл-ине с картами впереди борд раздается дважды, банк делится пополам
+	InsurancePause     bool // олл-ин до ривера не открывает борд до вызова RunOut, чтобы успели застраховаться
+	PotCap             int  // фишки сверх этого размера банка возвращаются игрокам перед вскрытием, 0 - без ограничения
+	MinHandsPerLevel   int  // блайнды не растут, пока на уровне не сыграно столько раздач
+	MinBetByStreet     map[Street]int
+	BadBeatThreshold   HandRank // проигравшая комбинация не ниже этой получает джекпот, 0 - выключено
+	BadBeatPayout      int
+	Rake               float64 // доля каждого банка, которую забирает заведение
+	RakeCap            int     // максимум рейка за раздачу, 0 - без ограничения
+	AnteType           AnteType
+	PartialAnte        bool     // игрок, которому не хватает на анте, ставит остаток и идет олл-ин вместо выбывания
+	Shuffler           Shuffler `json:"-"` // если не задан, колода тасуется внутри по Seed
+	Deterministic      bool     // сид следующей раздачи выводится только из текущего, для тестов и повторов
+}
+
+// BlindPositions remembers who posted the blinds in the last hand. The indexes are shifted
+// together with PlayersOrder, so the button and the blinds keep moving by seats when
+// players leave: the big blind always advances, the small blind and the button may be dead.
+type BlindPositions struct {
+	SmallBlindIndex int // у мертвого малого блайнда - место игрока перед ним
+	BigBlindIndex   int
+	SmallBlind      string // пусто, если малый блайнд был мертвым
+	BigBlind        string // пусто до первой раздачи
+	DeadButton      bool
 }
 
-// TODO add timeout for 1 move and time bank
 type TableMeta struct {
-	SmallBlind     int
-	Ante           int
-	DealerIndex    int
-	PlayerTurnInd  int
-	CurrentBet     int
-	CommunityCards []Card
-	PlayersOrder   []string
-	Players        map[string]IPlayer
-	Query          map[string]IPlayer
-	Pots           []Pot
-	Deck           []Card
-	CurrentRound   int
-	GameStarted    bool
-	Seed           int64
+	SmallBlind        int
+	Ante              int
+	HandsAtLevel      int
+	HandsPlayed       int
+	StartedAt         time.Time // начало первой раздачи турнира
+	BlindLevel        int       // индекс текущего уровня в TableConfig.BlindLevels
+	DealerIndex       int
+	Blinds            BlindPositions
+	PlayerTurnInd     int
+	CurrentBet        int
+	LastRaise         int // размер последнего полного рейза на текущей улице
+	StreetRaises      int
+	LastAggressor     string // последний поставивший или повысивший на текущей улице
+	Straddle          string // кто поставил страддл в текущей раздаче
+	CommunityCards    []Card
+	NewCommunityCards []Card // карты, открытые на текущей улице
+	SecondBoard       []Card // второй борд при RunItTwice, пусто если борд раздавался один раз
+	PlayersOrder      []string
+	Players           map[string]IPlayer
+	Query             map[string]IPlayer
+	QueryOrder        []string       // игроки из Query в порядке прихода
+	Reservations      map[string]int // playerId -> сколько раздач место остается за игроком
+	Disconnected      map[string]bool
+	TimeBanks         map[string]time.Duration // оставшийся банк времени игроков
+	Rebuys            map[string]int           // сколько раз докупился каждый игрок
+	AddOns            map[string]bool
+	SittingOut        map[string]bool // пропускают раздачи, не освобождая место
+	Eliminated        []string        // выбывшие из турнира в порядке вылета
+	Pots              []Pot
+	ShowdownResults   []PotResult
+	Rake              int // рейк последней раздачи
+	Insurance         map[string]Insurance
+	RunOutPending     bool // торги закрыты олл-ином, борд ждет RunOut
+	Deck              []Card
+	Discards          []Card // сожженные карты текущей раздачи
+	ShuffleProof      []byte
+	CurrentRound      int
+	GameStarted       bool
+	Paused            bool
+	PausedAt          time.Time
+	Seed              int64
 }
 
 type PokerTable struct {
-	observers []IObserver
-	mu        sync.Mutex
-	Config    *TableConfig
-	Meta      *TableMeta
+	observers    []playerObserver
+	seated       []playerObserver
+	observerSeq  int // номер последней подписки
+	mu           sync.Mutex
+	history      HandHistory
+	record       *HandRecord // запись текущей раздачи
+	records      []HandRecord
+	rng          SwapShuffler
+	clock        Clock
+	timer        Timer
+	stopTimer    func() bool
+	moveSeq      int // номер ожидаемого хода, устаревший таймер не срабатывает
+	bankUser     string
+	bankStart    time.Time // когда bankUser начал тратить банк времени
+	moveDeadline time.Time // когда истечет время на ход, ноль - таймер не идет или идет банк
+	antePosted   bool      // анте текущей раздачи уже собрано
+	runTwice     bool      // торги закончились олл-ином, второй борд раздается при вскрытии
+	runTwiceFrom int       // сколько общих карт было открыто к концу торгов
+	runningOut   bool      // RunOut уже открывает борд, снова не останавливаемся
+	Config       *TableConfig
+	Meta         *TableMeta
 }
 
 func NewTableConfig(BlindIncreaseTime time.Duration, maxPlayers, minPlayers, bankAmount int, enterAfteStart bool) *TableConfig {
@@ -73,42 +275,179 @@ func NewTableConfig(BlindIncreaseTime time.Duration, maxPlayers, minPlayers, ban
 		MinPlayers:        minPlayers,
 		EnterAfterStart:   enterAfteStart,
 		BankAmount:        bankAmount,
+		HoleCards:         2,
+	}
+}
+
+// Validate reports the first setting that makes the table unplayable
+func (c *TableConfig) Validate() error {
+	if c.MaxPlayers < 2 {
+		return fmt.Errorf("%w: got %d", ErrInvalidMaxPlayers, c.MaxPlayers)
+	}
+	if c.MinPlayers < 0 || c.MinPlayers > c.MaxPlayers {
+		return fmt.Errorf("%w: got %d with max %d", ErrInvalidMinPlayers, c.MinPlayers, c.MaxPlayers)
 	}
+	return nil
 }
 
 func NewTableMeta(smallBlind int, ante int, seed int64) *TableMeta {
 	return &TableMeta{
-		SmallBlind:     smallBlind,
-		Ante:           ante,
-		DealerIndex:    0,
-		PlayerTurnInd:  0,
-		CurrentBet:     0,
-		CommunityCards: []Card{},
-		PlayersOrder:   make([]string, 0, 10),
-		Players:        make(map[string]IPlayer),
-		Query:          make(map[string]IPlayer),
-		Pots:           []Pot{},
-		Deck:           []Card{},
-		CurrentRound:   -1,
-		GameStarted:    false,
-		Seed:           seed,
+		SmallBlind:      smallBlind,
+		Ante:            ante,
+		DealerIndex:     0,
+		PlayerTurnInd:   0,
+		CurrentBet:      0,
+		CommunityCards:  []Card{},
+		PlayersOrder:    make([]string, 0, 10),
+		Players:         make(map[string]IPlayer),
+		Query:           make(map[string]IPlayer),
+		QueryOrder:      []string{},
+		Reservations:    make(map[string]int),
+		Disconnected:    make(map[string]bool),
+		TimeBanks:       make(map[string]time.Duration),
+		Rebuys:          make(map[string]int),
+		AddOns:          make(map[string]bool),
+		SittingOut:      make(map[string]bool),
+		Pots:            []Pot{},
+		ShowdownResults: []PotResult{},
+		Insurance:       make(map[string]Insurance),
+		Deck:            []Card{},
+		Discards:        []Card{},
+		CurrentRound:    RoundPreDeal,
+		GameStarted:     false,
+		Seed:            seed,
+	}
+}
+
+// Validate reports a negative small blind or ante
+func (m *TableMeta) Validate() error {
+	if m.SmallBlind < 0 {
+		return fmt.Errorf("%w: got %d", ErrInvalidBlind, m.SmallBlind)
+	}
+	if m.Ante < 0 {
+		return fmt.Errorf("%w: got %d", ErrInvalidAnte, m.Ante)
+	}
+	return nil
+}
+
+// clone returns a deep copy of the meta, players are copied with IPlayer.Copy
+func (m *TableMeta) clone() *TableMeta {
+	c := *m
+	c.CommunityCards = slices.Clone(m.CommunityCards)
+	c.NewCommunityCards = slices.Clone(m.NewCommunityCards)
+	c.SecondBoard = slices.Clone(m.SecondBoard)
+	c.PlayersOrder = slices.Clone(m.PlayersOrder)
+	c.Players = make(map[string]IPlayer, len(m.Players))
+	for k, v := range m.Players {
+		c.Players[k] = v.Copy()
+	}
+	c.Query = make(map[string]IPlayer, len(m.Query))
+	for k, v := range m.Query {
+		c.Query[k] = v.Copy()
+	}
+	c.QueryOrder = slices.Clone(m.QueryOrder)
+	c.Reservations = maps.Clone(m.Reservations)
+	c.Disconnected = maps.Clone(m.Disconnected)
+	c.TimeBanks = maps.Clone(m.TimeBanks)
+	c.Rebuys = maps.Clone(m.Rebuys)
+	c.AddOns = maps.Clone(m.AddOns)
+	c.SittingOut = maps.Clone(m.SittingOut)
+	c.Eliminated = slices.Clone(m.Eliminated)
+	c.Pots = make([]Pot, 0, len(m.Pots))
+	for _, pot := range m.Pots {
+		c.Pots = append(c.Pots, Pot{Amount: pot.Amount, Applicants: slices.Clone(pot.Applicants)})
+	}
+	c.ShowdownResults = make([]PotResult, 0, len(m.ShowdownResults))
+	for _, res := range m.ShowdownResults {
+		c.ShowdownResults = append(c.ShowdownResults, PotResult{Amount: res.Amount, Winners: slices.Clone(res.Winners), Margin: res.Margin, Low: res.Low})
 	}
+	c.Insurance = maps.Clone(m.Insurance)
+	c.Deck = slices.Clone(m.Deck)
+	c.Discards = slices.Clone(m.Discards)
+	c.ShuffleProof = slices.Clone(m.ShuffleProof)
+	return &c
 }
 
 func NewPokerTable(config *TableConfig, meta *TableMeta) *PokerTable {
 	return &PokerTable{
-		observers: []IObserver{},
+		observers: []playerObserver{},
 		mu:        sync.Mutex{},
 		Config:    config,
 		Meta:      meta,
 	}
 }
 
-func (m *TableMeta) refreshDeck() {
-	m.Deck = GetStandardDeck()
-	var r *rand.Rand
-	if m.Seed != 0 {
+// Shuffler shuffles the deck outside of the table, e.g. with a verifiable randomness service.
+// The returned proof is kept in TableMeta.ShuffleProof for later verification.
+type Shuffler interface {
+	Shuffle(deck []Card) ([]Card, []byte, error)
+}
+
+// SetClock replaces the clock used for the blind schedule, nil restores time.Now
+func (t *PokerTable) SetClock(c Clock) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.clock = c
+}
+
+// SetTimer replaces the timer used for the move timeout, nil restores time.AfterFunc
+func (t *PokerTable) SetTimer(timer Timer) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timer = timer
+}
+
+func (t *PokerTable) now() time.Time {
+	if t.clock == nil {
+		return time.Now()
+	}
+	return t.clock.Now()
+}
+
+// SwapShuffler permutes n elements with swap, *rand.Rand satisfies it.
+// It replaces the seeded math/rand of the internal shuffle.
+type SwapShuffler interface {
+	Shuffle(n int, swap func(i, j int))
+}
+
+// SetRand makes the internal shuffle use r, nil restores the shuffle by TableMeta.Seed.
+// A Shuffler from the config still takes precedence.
+func (t *PokerTable) SetRand(r SwapShuffler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rng = r
+}
+
+// shuffleDeck prepares a new deck with the configured Shuffler or with the internal shuffle
+func (t *PokerTable) shuffleDeck() error {
+	if t.Config.Shuffler == nil {
+		t.Meta.refreshDeck(t.newDeck(), t.rng)
+		t.Meta.ShuffleProof = nil
+		return nil
+	}
+	deck, proof, err := t.Config.Shuffler.Shuffle(t.newDeck())
+	if err != nil {
+		return err
+	}
+	t.Meta.Deck = deck
+	t.Meta.ShuffleProof = proof
+	return nil
+}
+
+// newDeck returns the unshuffled deck of the variant played at the table
+func (t *PokerTable) newDeck() []Card {
+	if t.Config.ShortDeck {
+		return GetShortDeck()
+	}
+	return GetStandardDeck()
+}
+
+func (m *TableMeta) refreshDeck(deck []Card, r SwapShuffler) {
+	m.Deck = deck
+	if r == nil && m.Seed != 0 {
 		r = rand.New(rand.NewSource(m.Seed))
+	} else if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano())) // при нулевом сиде колода тасуется случайно
 	}
 	r.Shuffle(len(m.Deck), func(i, j int) {
 		m.Deck[i], m.Deck[j] = m.Deck[j], m.Deck[i]
@@ -122,27 +461,116 @@ func (m *TableMeta) addPlayerInGame(p IPlayer) {
 //TODO remove player
 
 func (m *TableMeta) addPlayerInQuery(p IPlayer) {
+	if _, ok := m.Query[p.GetId()]; !ok {
+		m.QueryOrder = append(m.QueryOrder, p.GetId())
+	}
 	m.Query[p.GetId()] = p
 }
 
-func (t *PokerTable) AddObserver(obs IObserver) {
-	t.observers = append(t.observers, obs)
+// AddObserver subscribes obs to the table events. Observers are notified while the table is locked,
+// so they must not call the table methods from Update. The first event obs gets is EventStateSnapshot
+// with the current public state.
+// AddObserver subscribes obs to every event of the table. The returned function detaches it,
+// unlike RemoveObserver it works for observers of any type.
+func (t *PokerTable) AddObserver(obs IObserver) (remove func() bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	po := t.newObserver("", obs)
+	t.observers = append(t.observers, po)
+	t.sendSnapshot(obs)
+	return t.observerRemover(po.id)
+}
+
+func (t *PokerTable) newObserver(playerId string, obs IObserver) playerObserver {
+	t.observerSeq++
+	return playerObserver{id: t.observerSeq, playerId: playerId, obs: obs}
+}
+
+// observerRemover returns the function detaching the subscription id
+func (t *PokerTable) observerRemover(id int) func() bool {
+	return func() bool {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.removeObservers(func(po playerObserver) bool { return po.id == id })
+	}
+}
+
+func (t *PokerTable) removeObservers(match func(po playerObserver) bool) bool {
+	n := len(t.observers) + len(t.seated)
+	t.observers = slices.DeleteFunc(t.observers, match)
+	t.seated = slices.DeleteFunc(t.seated, match)
+	return len(t.observers)+len(t.seated) < n
+}
+
+// sendSnapshot lets a new observer catch up with a hand already in progress
+func (t *PokerTable) sendSnapshot(obs IObserver) {
+	state := t.getState()
+	obs.Update(Event{Kind: EventStateSnapshot, Round: state.Round, State: &state})
+}
+
+// AddPlayerObserver subscribes obs on behalf of the player. It gets the public events and only
+// the private events of this player, observers added with AddObserver get everything.
+// The returned function detaches the observer like the one of AddObserver.
+func (t *PokerTable) AddPlayerObserver(playerId string, obs IObserver) (remove func() bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	po := t.newObserver(playerId, obs)
+	t.seated = append(t.seated, po)
+	t.sendSnapshot(obs)
+	return t.observerRemover(po.id)
+}
+
+// RemoveObserver detaches the observer added with AddObserver or AddPlayerObserver.
+// It reports whether the observer was subscribed. An observer whose type is not comparable,
+// a func or a struct with a slice, is never found: detach it with the function returned on adding.
+func (t *PokerTable) RemoveObserver(obs IObserver) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	comparable := func(o IObserver) bool { return reflect.ValueOf(o).Comparable() } // иначе == паникует
+	if obs == nil || !comparable(obs) {
+		return false
+	}
+	return t.removeObservers(func(po playerObserver) bool { return comparable(po.obs) && po.obs == obs })
 }
 
-func (t *PokerTable) NotifyObservers(event string) {
-	for _, obs := range t.observers {
-		obs.Update(event)
+func (t *PokerTable) NotifyObservers(event Event) {
+	t.recordEvent(event)
+	for _, po := range t.observers {
+		po.obs.Update(event)
+	}
+	for _, po := range t.seated {
+		if event.Private() && po.playerId != event.PlayerID { // чужие карманные карты не показываем
+			continue
+		}
+		po.obs.Update(event)
 	}
 }
 
 func (t *PokerTable) AddPlayer(p IPlayer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if t.Meta.GameStarted && !t.Config.EnterAfterStart {
 		return ErrGameStarted
 	}
+	_, seated := t.Meta.Players[p.GetId()]
+	_, queued := t.Meta.Query[p.GetId()]
+	if seated || queued {
+		return ErrDuplicatePlayer
+	}
+	if t.Config.BankAmount <= 0 { // в турнире стек задает BankAmount
+		if err := t.checkBuyIn(p.GetBalance()); err != nil {
+			return err
+		}
+	}
 
-	if t.Config.MaxPlayers <= len(t.Meta.Players)+len(t.Meta.Query)+1 {
+	reserved := len(t.Meta.Reservations)
+	if _, ok := t.Meta.Reservations[p.GetId()]; ok {
+		reserved--
+	}
+	if len(t.Meta.Players)+len(t.Meta.Query)+reserved >= t.Config.MaxPlayers {
 		return ErrMaxPlayers
 	}
+	delete(t.Meta.Reservations, p.GetId())
 
 	if t.Meta.GameStarted {
 		t.Meta.addPlayerInQuery(p)
@@ -150,83 +578,512 @@ func (t *PokerTable) AddPlayer(p IPlayer) error {
 		t.Meta.addPlayerInGame(p)
 		t.Meta.PlayersOrder = append(t.Meta.PlayersOrder, p.GetId())
 	}
-	t.NotifyObservers(fmt.Sprintf("Player %s enter the game", p.GetId()))
+	t.NotifyObservers(Event{Kind: EventPlayerJoined, PlayerID: p.GetId()})
+	return nil
+}
+
+// SetSeating places the seated players in the given order before the game starts.
+// The order must contain every seated player exactly once.
+func (t *PokerTable) SetSeating(order []string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Meta.GameStarted {
+		return ErrGameStarted
+	}
+	if len(order) != len(t.Meta.Players) {
+		return ErrInvalidSeating
+	}
+	seen := make(map[string]bool, len(order))
+	for _, k := range order {
+		if _, ok := t.Meta.Players[k]; !ok || seen[k] {
+			return ErrInvalidSeating
+		}
+		seen[k] = true
+	}
+	t.Meta.PlayersOrder = slices.Clone(order)
+	t.Meta.Blinds = BlindPositions{} // после пересадки баттон двигается заново
+	t.NotifyObservers(Event{Kind: EventSeatingSet, Players: slices.Clone(order)})
 	return nil
 }
 
+// PendingEntrants returns the queued players who will be seated at the next preflop in arrival order
+func (t *PokerTable) PendingEntrants() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.pendingEntrants()
+}
+
+func (t *PokerTable) pendingEntrants() []string {
+	free := t.Config.MaxPlayers - len(t.Meta.Players)
+	entrants := make([]string, 0, len(t.Meta.QueryOrder))
+	for _, k := range t.Meta.QueryOrder {
+		if len(entrants) >= free {
+			break
+		}
+		entrants = append(entrants, k)
+	}
+	return entrants
+}
+
 func (t *PokerTable) enterPlayersFromQuery() {
-	for k, v := range t.Meta.Query {
-		t.Meta.Players[k] = v
+	for _, k := range t.pendingEntrants() {
+		t.Meta.Players[k] = t.Meta.Query[k]
 		t.Meta.PlayersOrder = append(t.Meta.PlayersOrder, k)
+		delete(t.Meta.Query, k)
 	}
+	t.Meta.QueryOrder = slices.DeleteFunc(t.Meta.QueryOrder, func(k string) bool {
+		_, ok := t.Meta.Query[k]
+		return !ok
+	})
 }
 
 func (t *PokerTable) StartGame() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if t.Meta.GameStarted {
 		return ErrGameStarted
 	}
-	//TODO change balance if bankamount != 0
+	if err := t.Config.Validate(); err != nil {
+		return err
+	}
+	if err := t.Meta.Validate(); err != nil {
+		return err
+	}
+	if t.Config.HoleCards < 0 {
+		return ErrInvalidHoleCards
+	}
+	if t.Config.Omaha && t.Config.HoleCards < 2 {
+		return ErrOmahaHoleCards
+	}
+	if t.gameOver() {
+		return ErrGameOver
+	}
+	if t.Meta.Paused {
+		return ErrGamePaused
+	}
+	active := 0
+	for _, k := range slices.Concat(t.Meta.PlayersOrder, t.pendingEntrants()) {
+		if !t.Meta.SittingOut[k] {
+			active++
+		}
+	}
+	if active < 2 && len(t.Meta.SittingOut) > 0 {
+		return ErrNotEnoughPlayers
+	}
+	if t.Meta.StartedAt.IsZero() {
+		t.Meta.StartedAt = t.now()
+	}
+	if t.Config.BankAmount > 0 && t.Meta.HandsPlayed == 0 { // турнир: все начинают с одинаковым стеком
+		for _, k := range t.Meta.PlayersOrder {
+			p := t.Meta.Players[k]
+			p.ChangeBalance(t.Config.BankAmount - p.GetBalance())
+		}
+	}
+	if err := t.shuffleDeck(); err != nil {
+		return err
+	}
+	if err := t.checkDeck(); err != nil {
+		return err
+	}
+	t.recordHandStart()
 	t.Meta.GameStarted = true
-	t.Meta.CurrentRound = -1
-	t.Meta.refreshDeck()
-	t.NotifyObservers("Game started")
-	t.NewRound()
+	t.Meta.CurrentRound = RoundPreDeal
+	t.NotifyObservers(Event{Kind: EventGameStarted})
+	t.newRound()
+	t.skipDisconnected()
 	return nil
 }
 
 func (t *PokerTable) NewRound() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Meta.Paused {
+		return ErrGamePaused
+	}
+	return t.newRound()
+}
+
+// Pause stops the game for a break: moves are rejected, the move timer is stopped
+// and the time until the next blind level is frozen until Resume
+func (t *PokerTable) Pause() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Meta.Paused {
+		return ErrGamePaused
+	}
+	t.Meta.Paused = true
+	t.Meta.PausedAt = t.now()
+	t.startMoveTimer() // на паузе только останавливает таймер и списывает банк времени
+	t.NotifyObservers(Event{Kind: EventPaused})
+	return nil
+}
+
+// Resume continues the paused game. The blind level clock is shifted by the pause duration
+// and the player to act gets a fresh move timer.
+func (t *PokerTable) Resume() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.Meta.Paused {
+		return ErrGameNotPaused
+	}
+	paused := t.now().Sub(t.Meta.PausedAt)
+	t.Config.LastBlindIncrease = t.Config.LastBlindIncrease.Add(paused)
+	t.Meta.Paused = false
+	t.Meta.PausedAt = time.Time{}
+	t.NotifyObservers(Event{Kind: EventResumed, Duration: paused})
+	t.skipDisconnected()
+	return nil
+}
+
+func (t *PokerTable) newRound() error {
 	if !t.Meta.GameStarted {
+		if t.gameOver() {
+			return ErrGameOver
+		}
 		return ErrGameNotStarted
 	}
 	t.createPots()
+	if t.Meta.CurrentRound != RoundRiver { // на вскрытии первым открывается агрессор ривера
+		t.Meta.LastAggressor = ""
+	}
 	t.Meta.CurrentRound += 1
 	t.Meta.CurrentBet = 0
-	t.NotifyObservers(fmt.Sprintf("New round started. Current round: %d", t.Meta.CurrentRound))
+	t.Meta.LastRaise = 0
+	t.Meta.StreetRaises = 0
+	t.NotifyObservers(Event{Kind: EventNewRound, Round: t.Meta.CurrentRound})
 
-	refreshPlayers(t.Meta.Players, t.Meta.CurrentRound == 4)
+	refreshPlayers(t.Meta.Players, false)
 	switch t.Meta.CurrentRound {
-	case 0: //pre flop
+	case RoundPreflop:
+		t.maybeIncreaseBlinds()
 		t.enterPlayersFromQuery()
+		t.dealOutSittingOut()
 		t.betAnte()
-		for _, k := range t.Meta.PlayersOrder {
-			cards, _ := t.drawCard(2)
-			t.Meta.Players[k].SetHand(Hand{[2]Card{cards[0], cards[1]}})
-			t.NotifyObservers(fmt.Sprintf("Player %s get cards: %v", t.Meta.Players[k].GetId(), cards))
-		}
+		t.refillTimeBanks()
+		t.dealHoleCards()
 		t.choiceDealer()
 		t.betBlinds()
-	case 1: // flop
+		t.Meta.CommunityCards = []Card{}
+		t.Meta.NewCommunityCards = []Card{}
+		t.Meta.SecondBoard = []Card{}
+		t.Meta.ShowdownResults = []PotResult{}
+		t.Meta.Discards = []Card{}
+		t.Meta.Rake = 0
+	case RoundFlop:
+		t.burnCard()
 		t.Meta.CommunityCards, _ = t.drawCard(3)
-		t.NotifyObservers(fmt.Sprintf("Community cards: %v", t.Meta.CommunityCards))
+		t.Meta.NewCommunityCards = slices.Clone(t.Meta.CommunityCards)
+		t.NotifyObservers(Event{Kind: EventCommunityCards, Cards: slices.Clone(t.Meta.CommunityCards), Round: t.Meta.CurrentRound})
 		t.Meta.PlayerTurnInd = (t.Meta.DealerIndex + 1) % len(t.Meta.PlayersOrder)
 
-	case 2: // turn
+	case RoundTurn:
+		t.burnCard()
 		cards, _ := t.drawCard(1)
 		t.Meta.CommunityCards = append(t.Meta.CommunityCards, cards...)
-		t.NotifyObservers(fmt.Sprintf("Community cards: %v", t.Meta.CommunityCards))
+		t.Meta.NewCommunityCards = cards
+		t.NotifyObservers(Event{Kind: EventCommunityCards, Cards: slices.Clone(t.Meta.CommunityCards), Round: t.Meta.CurrentRound})
 
-	case 3: // river
+	case RoundRiver:
+		t.burnCard()
 		cards, _ := t.drawCard(1)
 		t.Meta.CommunityCards = append(t.Meta.CommunityCards, cards...)
-		t.NotifyObservers(fmt.Sprintf("Community cards: %v", t.Meta.CommunityCards))
+		t.Meta.NewCommunityCards = cards
+		t.NotifyObservers(Event{Kind: EventCommunityCards, Cards: slices.Clone(t.Meta.CommunityCards), Round: t.Meta.CurrentRound})
 
-	case 4: // determinate winner
-		t.PayMoney()
-		t.Meta.updateSeed()
-		t.Meta.GameStarted = false
-		t.Meta.CurrentRound = -1
-		t.Meta.Pots = t.Meta.Pots[:0]
+	case RoundShowdown:
+		if t.runTwice {
+			t.dealSecondBoard()
+		}
+		t.revealHands()
+		t.payMoney()
+		t.finishHand()
 	}
 	t.choiceFirstMovePlayer()
+	if t.Meta.GameStarted && t.bettingClosed() && !t.holdRunOut() { // торговаться некому, открываем карты до конца
+		// второй раз раздаются и карты только что открытой улицы: торги закончились до нее
+		if t.Config.RunItTwice && !t.runTwice && t.Meta.CurrentRound <= RoundRiver && t.playersInHand() > 1 {
+			t.runTwice, t.runTwiceFrom = true, len(t.Meta.CommunityCards)-len(t.Meta.NewCommunityCards)
+		}
+		t.NotifyObservers(Event{Kind: EventRunOut, Round: t.Meta.CurrentRound})
+		return t.newRound()
+	}
 
 	return nil
 }
 
-func (m *TableMeta) updateSeed() {
+// holdRunOut stops the hand with InsurancePause when betting is closed before the river,
+// the next street waits for RunOut. It reports whether the hand was stopped.
+func (t *PokerTable) holdRunOut() bool {
+	if !t.Config.InsurancePause || t.runningOut || t.Meta.CurrentRound >= RoundRiver || !t.bettingClosed() || t.playersInHand() < 2 {
+		return false
+	}
+	t.Meta.RunOutPending = true
+	t.NotifyObservers(Event{Kind: EventRunOutPending, Round: t.Meta.CurrentRound})
+	return true
+}
+
+// revealHands opens the hands left at the showdown starting from the last aggressor of the river,
+// or from the first player left of the button if nobody bet, and going clockwise.
+// With MuckLosers a hand beaten by an already shown one is mucked unless it wins some pot.
+func (t *PokerTable) revealHands() {
+	eval := t.evaluator()
+	n := len(t.Meta.PlayersOrder)
+	start := t.nextSeated(t.Meta.DealerIndex)
+	if i := slices.Index(t.Meta.PlayersOrder, t.Meta.LastAggressor); i >= 0 && !t.Meta.Players[t.Meta.LastAggressor].GetFold() {
+		start = i
+	}
+	winners := t.potWinners()
+	best := -1
+	for i := range n {
+		k := t.Meta.PlayersOrder[(start+i)%n]
+		p := t.Meta.Players[k]
+		if p.GetFold() {
+			continue
+		}
+		comb := eval(p.GetHand().Cards, t.Meta.CommunityCards)
+		if t.Config.MuckLosers && !winners[k] && comb.score() < best {
+			t.NotifyObservers(Event{Kind: EventMuck, PlayerID: k})
+			continue
+		}
+		best = max(best, comb.score())
+		t.NotifyObservers(Event{Kind: EventReveal, PlayerID: k, Cards: slices.Clone(p.GetHand().Cards), Rank: comb.Rank})
+	}
+}
+
+// potWinners returns the players who win at least a part of some pot at the showdown
+func (t *PokerTable) potWinners() map[string]bool {
+	winners := map[string]bool{}
+	for _, pot := range t.Meta.Pots {
+		applicants := make(map[string]IPlayer)
+		for _, k := range pot.Applicants {
+			if p := t.Meta.Players[k]; !p.GetFold() {
+				applicants[k] = p
+			}
+		}
+		for _, board := range t.boards() {
+			ids, _ := determinateWinner(board, applicants, t.evaluator())
+			if t.Config.HiLo {
+				ids = append(ids, determinateLowWinners(board, applicants, t.Config.Omaha)...)
+			}
+			for _, k := range ids {
+				winners[k] = true
+			}
+		}
+	}
+	return winners
+}
+
+// takeRake returns the rake of the pot within what is left of RakeCap for this hand
+func (t *PokerTable) takeRake(amount int) int {
+	rake := int(float64(amount) * t.Config.Rake)
+	if t.Config.RakeCap > 0 {
+		rake = min(rake, t.Config.RakeCap-t.Meta.Rake)
+	}
+	rake = max(rake, 0)
+	t.Meta.Rake += rake
+	return rake
+}
+
+// LastRake returns the rake taken from the pots of the last finished hand
+func (t *PokerTable) LastRake() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Meta.Rake
+}
+
+// finishHand settles the side bets and resets the table for the next hand once the pots are paid
+func (t *PokerTable) finishHand() {
+	t.settleInsurance()
+	t.NotifyObservers(Event{Kind: EventHandEnded, Amount: t.Meta.Rake})
+	refreshPlayers(t.Meta.Players, true)
+	t.Meta.updateSeed(t.Config.Deterministic)
+	t.Meta.GameStarted = false
+	t.Meta.CurrentRound = RoundPreDeal
+	t.antePosted = false
+	t.runTwice, t.runTwiceFrom = false, 0
+	t.Meta.Pots = t.Meta.Pots[:0]
+	t.Meta.HandsAtLevel++
+	t.Meta.HandsPlayed++
+	t.releaseReservations()
+	t.eliminateBusted()
+	if t.gameOver() {
+		t.NotifyObservers(Event{Kind: EventGameOver, PlayerID: t.winner()})
+	}
+}
+
+// eliminateBusted removes the tournament players left without chips once rebuys are closed.
+// Players busted in the same hand are placed by their stacks at the start of the hand,
+// the smaller stack finishes lower.
+func (t *PokerTable) eliminateBusted() {
+	if t.Config.BankAmount <= 0 || t.rebuyOpen() {
+		return
+	}
+	busted := []string{}
+	for _, k := range t.Meta.PlayersOrder {
+		if t.Meta.Players[k].GetBalance() == 0 {
+			busted = append(busted, k)
+		}
+	}
+	if len(busted) == 0 {
+		return
+	}
+	start := make(map[string]int, len(t.history.Seats))
+	for _, seat := range t.history.Seats {
+		start[seat.PlayerId] = seat.Balance
+	}
+	slices.SortStableFunc(busted, func(a, b string) int { return cmp.Compare(start[a], start[b]) })
+	for _, k := range busted {
+		place := len(t.Meta.PlayersOrder) + len(t.Meta.Query)
+		t.removePlayer(k)
+		t.Meta.Eliminated = append(t.Meta.Eliminated, k)
+		t.NotifyObservers(Event{Kind: EventEliminated, PlayerID: k, Index: place})
+	}
+}
+
+// fundedPlayers returns the seated and queued players who still have chips
+func (t *PokerTable) fundedPlayers() []string {
+	funded := []string{}
+	for _, k := range slices.Concat(t.Meta.PlayersOrder, t.Meta.QueryOrder) {
+		p, ok := t.Meta.Players[k]
+		if !ok {
+			p = t.Meta.Query[k]
+		}
+		if p != nil && p.GetBalance() > 0 {
+			funded = append(funded, k)
+		}
+	}
+	return funded
+}
+
+// gameOver reports whether no more hands can be dealt: a single tournament player holds
+// all the chips, or fewer than MinPlayers cash players have chips left
+func (t *PokerTable) gameOver() bool {
+	if t.Meta.HandsPlayed == 0 || t.Meta.GameStarted {
+		return false
+	}
+	if t.Config.BankAmount > 0 {
+		return !t.rebuyOpen() && len(t.fundedPlayers()) <= 1
+	}
+	return len(t.fundedPlayers()) < max(t.Config.MinPlayers, 2)
+}
+
+// winner returns the only player with chips left, empty if there is none or several
+func (t *PokerTable) winner() string {
+	funded := t.fundedPlayers()
+	if len(funded) != 1 {
+		return ""
+	}
+	return funded[0]
+}
+
+// IsGameOver reports whether the game is finished and no new hand can be started
+func (t *PokerTable) IsGameOver() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.gameOver()
+}
+
+// Winner returns the last player standing once the game is over, empty otherwise
+func (t *PokerTable) Winner() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.gameOver() {
+		return ""
+	}
+	return t.winner()
+}
+
+// rebuyOpen reports whether the rebuy period of the tournament is still running
+func (t *PokerTable) rebuyOpen() bool {
+	if t.Config.RebuyPeriod <= 0 {
+		return false
+	}
+	return t.Meta.StartedAt.IsZero() || t.now().Sub(t.Meta.StartedAt) <= t.Config.RebuyPeriod
+}
+
+// winByFold gives all pots to the last player in the hand without dealing the rest of the board
+func (t *PokerTable) winByFold() {
+	winner := ""
+	for _, k := range t.Meta.PlayersOrder {
+		if !t.Meta.Players[k].GetFold() {
+			winner = k
+		}
+	}
+	t.returnUncalledBet()
+	total := t.totalPot() // вместе со ставками сбросивших игроков на текущей улице
+	total -= t.takeRake(total)
+	t.Meta.Players[winner].ChangeBalance(total)
+	t.Meta.ShowdownResults = append(t.Meta.ShowdownResults, PotResult{Amount: total, Winners: []string{winner}})
+	t.NotifyObservers(Event{Kind: EventWinByFold, PlayerID: winner, Amount: total})
+	t.finishHand()
+}
+
+// playersInHand counts the players who have not folded
+func (t *PokerTable) playersInHand() int {
+	count := 0
+	for _, v := range t.Meta.Players {
+		if !v.GetFold() {
+			count++
+		}
+	}
+	return count
+}
+
+// bettingClosed reports whether nobody can make a decision anymore: everybody in the hand is all-in
+// or the only player with chips has already matched the bet
+func (t *PokerTable) bettingClosed() bool {
+	active := 0
+	matched := true
+	for _, v := range t.Meta.Players {
+		if v.GetFold() || v.IsAllIn() {
+			continue
+		}
+		active++
+		matched = v.GetLastBet() >= t.Meta.CurrentBet
+	}
+	return active == 0 || (active == 1 && matched)
+}
+
+// maybeIncreaseBlinds moves to the next level of BlindLevels, or doubles the blinds and the ante
+// without a schedule, once BlindIncreaseTime has passed since the last increase and at least
+// MinHandsPerLevel hands were played on the current level.
+func (t *PokerTable) maybeIncreaseBlinds() {
+	if t.Config.BlindIncreaseTime <= 0 {
+		return
+	}
+	if t.now().Sub(t.Config.LastBlindIncrease) < t.Config.BlindIncreaseTime {
+		return
+	}
+	if t.Meta.HandsAtLevel < t.Config.MinHandsPerLevel {
+		return
+	}
+	if len(t.Config.BlindLevels) > 0 {
+		if t.Meta.BlindLevel+1 >= len(t.Config.BlindLevels) { // расписание закончилось, уровень остается последним
+			return
+		}
+		t.Meta.BlindLevel++
+		level := t.Config.BlindLevels[t.Meta.BlindLevel]
+		t.Meta.SmallBlind = level.SmallBlind
+		t.Meta.Ante = level.Ante
+	} else {
+		t.Meta.SmallBlind *= 2
+		t.Meta.Ante *= 2
+	}
+	t.Meta.HandsAtLevel = 0
+	t.Config.LastBlindIncrease = t.now()
+	t.NotifyObservers(Event{Kind: EventBlindsIncreased, Amount: t.Meta.SmallBlind, Ante: t.Meta.Ante})
+}
+
+// updateSeed picks the seed of the next hand. Outside of the deterministic mode the seed
+// is mixed with the system entropy, so the next decks can't be predicted from the current one.
+func (m *TableMeta) updateSeed(deterministic bool) {
 	if m.Seed != 0 {
 		r := rand.New(rand.NewSource(m.Seed))
 		for {
 			newSeed := r.Int63()
+			if !deterministic {
+				newSeed ^= entropy()
+			}
 			if newSeed == 0 {
 				continue
 			}
@@ -236,131 +1093,1060 @@ func (m *TableMeta) updateSeed() {
 	}
 }
 
-func (t *PokerTable) PayMoney() {
-	for ind, pot := range t.Meta.Pots {
-		applicants := make(map[string]IPlayer)
-		for _, k := range pot.Applicants {
-			p := t.Meta.Players[k]
-			if p.GetFold() { // если игрок сбросил то он не претендует на банк
-				continue
-			}
-			applicants[k] = p
-		}
-		winners, _ := DeterminateWinner(t.Meta.CommunityCards, applicants)
-		winAmount := pot.Amount / len(winners)
-		for _, winner := range winners {
-			t.Meta.Players[winner].ChangeBalance(winAmount)
-		}
-		t.NotifyObservers(fmt.Sprintf("Winners of pot %.2d with %d amount: %v", ind+1, winAmount, winners))
-		if winAmount*len(winners) == pot.Amount {
-			continue
-		}
-		counter := pot.Amount - winAmount*len(winners)
-		for i := 1; counter > 0; i++ {
-			targetPlayer := t.Meta.PlayersOrder[(t.Meta.DealerIndex+i)%len(t.Meta.Players)]
-			if t.Meta.Players[targetPlayer].GetFold() || !slices.Contains(winners, t.Meta.Players[targetPlayer].GetId()) {
-				continue
-			}
-			t.Meta.Players[targetPlayer].ChangeBalance(1)
-			counter--
+// entropy returns a random non-negative int64 from the system source
+func entropy() int64 {
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		return time.Now().UnixNano() & math.MaxInt64
+	}
+	return int64(binary.BigEndian.Uint64(buf[:]) & math.MaxInt64)
+}
+
+func (t *PokerTable) PayMoney() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.payMoney()
+}
+
+// mergeUncontestedPots adds every side pot whose applicants all folded to the pot below it
+func (t *PokerTable) mergeUncontestedPots() {
+	for i := len(t.Meta.Pots) - 1; i > 0; i-- {
+		contested := slices.ContainsFunc(t.Meta.Pots[i].Applicants, func(k string) bool {
+			return !t.Meta.Players[k].GetFold()
+		})
+		if !contested {
+			t.Meta.Pots[i-1].Amount += t.Meta.Pots[i].Amount
+			t.Meta.Pots = slices.Delete(t.Meta.Pots, i, i+1)
+		}
+	}
+}
+
+func (t *PokerTable) payMoney() {
+	t.capPots()
+	t.mergeUncontestedPots()
+	t.checkBadBeat()
+	for ind, pot := range t.Meta.Pots {
+		pot.Amount -= t.takeRake(pot.Amount)
+		applicants := make(map[string]IPlayer)
+		for _, k := range pot.Applicants {
+			p := t.Meta.Players[k]
+			if p.GetFold() { // если игрок сбросил то он не претендует на банк
+				continue
+			}
+			applicants[k] = p
+		}
+		boards := t.boards()
+		rest := pot.Amount
+		for i, board := range boards {
+			share := (rest + len(boards) - i - 1) / (len(boards) - i) // нечетная фишка достается первому борду
+			rest -= share
+			t.payBoard(ind, share, board, applicants)
+		}
+	}
+}
+
+// payBoard awards the amount of the pot to the best hands on the board
+func (t *PokerTable) payBoard(ind, amount int, board []Card, applicants map[string]IPlayer) {
+	winners, err := determinateWinner(board, applicants, t.evaluator())
+	if err != nil { // после mergeUncontestedPots не бывает
+		return
+	}
+	high := amount
+	if t.Config.HiLo {
+		if lowWinners := determinateLowWinners(board, applicants, t.Config.Omaha); len(lowWinners) > 0 {
+			high = (amount + 1) / 2 // нечетная фишка достается старшей руке
+			t.Meta.ShowdownResults = append(t.Meta.ShowdownResults, PotResult{Amount: amount - high, Winners: lowWinners, Low: true})
+			t.awardPot(ind, amount-high, lowWinners, nil)
+		}
+	}
+	t.Meta.ShowdownResults = append(t.Meta.ShowdownResults, PotResult{
+		Amount:  high,
+		Winners: winners,
+		Margin:  winningMargin(board, applicants, winners, t.evaluator()),
+	})
+	t.awardPot(ind, high, winners, board)
+}
+
+// boards returns the boards the pots are played on: the community cards and the second board
+// of RunItTwice
+func (t *PokerTable) boards() [][]Card {
+	if len(t.Meta.SecondBoard) == 0 {
+		return [][]Card{t.Meta.CommunityCards}
+	}
+	return [][]Card{t.Meta.CommunityCards, t.Meta.SecondBoard}
+}
+
+// dealSecondBoard deals the community cards after the all-in once more for RunItTwice.
+// If the deck runs out the hand is played on the single board.
+func (t *PokerTable) dealSecondBoard() {
+	board := slices.Clone(t.Meta.CommunityCards[:t.runTwiceFrom])
+	for len(board) < 5 {
+		n := 1
+		if len(board) == 0 { // флоп
+			n = 3
+		}
+		if err := t.burnCard(); err != nil {
+			return
+		}
+		cards, err := t.drawCard(n)
+		if err != nil {
+			return
+		}
+		board = append(board, cards...)
+	}
+	t.Meta.SecondBoard = board
+	t.NotifyObservers(Event{Kind: EventRunItTwice, Cards: slices.Clone(t.Meta.CommunityCards), Index: 1})
+	t.NotifyObservers(Event{Kind: EventRunItTwice, Cards: slices.Clone(board), Index: 2})
+}
+
+// awardPot splits the amount between the winners, the odd chips go one by one
+// to the winners closest to the left of the dealer. The low half of a HiLo pot has no board,
+// otherwise the event carries the winning hands on the board.
+func (t *PokerTable) awardPot(ind, amount int, winners []string, board []Card) {
+	winAmount := amount / len(winners)
+	for _, winner := range winners {
+		t.Meta.Players[winner].ChangeBalance(winAmount)
+	}
+	event := Event{Kind: EventWinner, Index: ind + 1, Amount: winAmount, Players: slices.Clone(winners), Low: board == nil}
+	if board != nil {
+		event.Hands = make(map[string][]Card, len(winners))
+		for _, k := range winners {
+			var comb Combination
+			comb, event.Hands[k] = bestFiveCards(t.Meta.Players[k].GetHand().Cards, board, t.evaluator(), t.Config.Omaha)
+			event.Rank = comb.Rank // у разделивших банк ранг одинаковый
+		}
+	}
+	t.NotifyObservers(event)
+	counter := amount - winAmount*len(winners)
+	for i := 1; counter > 0; i++ {
+		targetPlayer := t.Meta.PlayersOrder[(t.Meta.DealerIndex+i)%len(t.Meta.PlayersOrder)]
+		if t.Meta.Players[targetPlayer].GetFold() || !slices.Contains(winners, t.Meta.Players[targetPlayer].GetId()) {
+			continue
+		}
+		t.Meta.Players[targetPlayer].ChangeBalance(1)
+		counter--
+	}
+}
+
+// capPots refunds the chips above PotCap to the contributors in proportion to their
+// contributions and shrinks the pots accordingly
+func (t *PokerTable) capPots() {
+	total := 0
+	contributions := make(map[string]int)
+	for _, pot := range t.Meta.Pots {
+		total += pot.Amount
+		for _, k := range pot.Applicants {
+			contributions[k] += pot.Amount / len(pot.Applicants)
+		}
+	}
+	if t.Config.PotCap <= 0 || total <= t.Config.PotCap {
+		return
+	}
+
+	excess := total - t.Config.PotCap
+	refunds := make(map[string]int, len(contributions))
+	refunded := 0
+	for k, c := range contributions {
+		refunds[k] = excess * c / total
+		refunded += refunds[k]
+	}
+	for i := 0; refunded < excess; i++ { // остаток от округления по фишке по порядку мест
+		k := t.Meta.PlayersOrder[i%len(t.Meta.PlayersOrder)]
+		if contributions[k] > refunds[k] {
+			refunds[k]++
+			refunded++
+		}
+	}
+	for _, k := range t.Meta.PlayersOrder {
+		if refunds[k] == 0 {
+			continue
+		}
+		t.Meta.Players[k].ChangeBalance(refunds[k])
+		t.NotifyObservers(Event{Kind: EventPotCapRefund, PlayerID: k, Amount: refunds[k]})
+	}
+
+	left := t.Config.PotCap
+	for i := range t.Meta.Pots {
+		amount := t.Meta.Pots[i].Amount * t.Config.PotCap / total
+		if i == len(t.Meta.Pots)-1 {
+			amount = left
+		}
+		t.Meta.Pots[i].Amount = amount
+		left -= amount
+	}
+}
+
+// totalPot returns the chips already collected into pots plus the bets of the current round
+func (t *PokerTable) totalPot() int {
+	total := 0
+	for _, pot := range t.Meta.Pots {
+		total += pot.Amount
+	}
+	for _, v := range t.Meta.Players {
+		total += v.GetLastBet()
+	}
+	return total
+}
+
+// TotalPot returns all chips in the middle: the collected pots and the bets of the current street
+func (t *PokerTable) TotalPot() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.totalPot()
+}
+
+// PotDetails returns the main pot and the side pots as they would be if the current street ended now.
+// The bets of the street are layered by the all-in amounts, a bet nobody called yet stays in the last pot.
+func (t *PokerTable) PotDetails() []PotInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pots := []Pot{}
+	for _, pot := range t.Meta.Pots {
+		applicants := slices.DeleteFunc(slices.Clone(pot.Applicants), func(k string) bool {
+			p, ok := t.Meta.Players[k]
+			return !ok || p.GetFold()
+		})
+		pots = append(pots, Pot{Amount: pot.Amount, Applicants: applicants})
+	}
+	for _, pot := range t.streetPots() {
+		if last := len(pots) - 1; last >= 0 && slices.Equal(pots[last].Applicants, pot.Applicants) {
+			pots[last].Amount += pot.Amount
+			continue
+		}
+		pots = append(pots, pot)
+	}
+
+	details := make([]PotInfo, 0, len(pots))
+	for i, pot := range pots {
+		label := "main"
+		if i > 0 {
+			label = fmt.Sprintf("side %d", i)
+		}
+		details = append(details, PotInfo{Amount: pot.Amount, Applicants: pot.Applicants, Label: label})
+	}
+	return details
+}
+
+// streetPots layers the bets of the current street without collecting them. A layer ends
+// at the bet of an all-in player, the players who still can act are applicants of every layer.
+func (t *PokerTable) streetPots() []Pot {
+	levels := []int{0}
+	for _, v := range t.Meta.Players {
+		if v.IsAllIn() && !v.GetFold() {
+			levels = append(levels, v.GetLastBet())
+		}
+		levels[0] = max(levels[0], v.GetLastBet())
+	}
+	slices.Sort(levels)
+	levels = slices.Compact(levels)
+
+	pots := []Pot{}
+	prev := 0
+	for _, level := range levels {
+		amount := 0
+		for _, v := range t.Meta.Players {
+			amount += min(v.GetLastBet(), level) - min(v.GetLastBet(), prev)
+		}
+		if amount == 0 {
+			continue
+		}
+		applicants := []string{}
+		for k, v := range t.Meta.Players {
+			if !v.GetFold() && (!v.IsAllIn() || v.GetLastBet() >= level) {
+				applicants = append(applicants, k)
+			}
+		}
+		slices.Sort(applicants)
+		pots = append(pots, Pot{Amount: amount, Applicants: applicants})
+		prev = level
+	}
+	return pots
+}
+
+// CallBreakdown splits the call of the player across the pot layers formed by the bets of the
+// current street: every all-in below the call starts a new side pot. It returns nil when the
+// player has nothing to call.
+func (t *PokerTable) CallBreakdown(playerId string) []PotContribution {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.callBreakdown(playerId)
+}
+
+func (t *PokerTable) callBreakdown(playerId string) []PotContribution {
+	p, ok := t.Meta.Players[playerId]
+	if !ok || p.GetFold() {
+		return nil
+	}
+	target := p.GetLastBet() + min(max(t.Meta.CurrentBet-p.GetLastBet(), 0), p.GetBalance())
+	levels := []int{target}
+	for _, v := range t.Meta.Players {
+		if !v.GetFold() && v.GetLastBet() > p.GetLastBet() && v.GetLastBet() < target {
+			levels = append(levels, v.GetLastBet())
+		}
+	}
+	slices.Sort(levels)
+	levels = slices.Compact(levels)
+
+	var breakdown []PotContribution
+	prev := p.GetLastBet()
+	for _, level := range levels {
+		if level == prev {
+			continue
+		}
+		applicants := []string{}
+		for _, k := range t.Meta.PlayersOrder {
+			v := t.Meta.Players[k]
+			if k == playerId || (!v.GetFold() && v.GetLastBet() >= level) {
+				applicants = append(applicants, k)
+			}
+		}
+		breakdown = append(breakdown, PotContribution{Amount: level - prev, Applicants: applicants})
+		prev = level
+	}
+	return breakdown
+}
+
+// PotInBB returns the current pot expressed in big blinds.
+func (t *PokerTable) PotInBB() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.potInBB()
+}
+
+func (t *PokerTable) potInBB() float64 {
+	bigBlind := t.Meta.SmallBlind * 2
+	if bigBlind == 0 {
+		return 0
+	}
+	return float64(t.totalPot()) / float64(bigBlind)
+}
+
+// StackInBB returns the balance of the player expressed in big blinds.
+func (t *PokerTable) StackInBB(playerId string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stackInBB(playerId)
+}
+
+func (t *PokerTable) stackInBB(playerId string) float64 {
+	bigBlind := t.Meta.SmallBlind * 2
+	p, ok := t.Meta.Players[playerId]
+	if !ok || bigBlind == 0 {
+		return 0
+	}
+	return float64(p.GetBalance()) / float64(bigBlind)
+}
+
+// PotCommitment returns the share of the starting stack the player has already put
+// into the current hand: 0 for nothing invested, 1 for all-in.
+func (t *PokerTable) PotCommitment(playerId string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.potCommitment(playerId)
+}
+
+func (t *PokerTable) potCommitment(playerId string) float64 {
+	p, ok := t.Meta.Players[playerId]
+	if !ok || !t.Meta.GameStarted {
+		return 0
+	}
+	for _, seat := range t.history.Seats { // стек на момент старта раздачи
+		if seat.PlayerId == playerId && seat.Balance > 0 {
+			return float64(seat.Balance-p.GetBalance()) / float64(seat.Balance)
+		}
+	}
+	return 0
+}
+
+// Rebuy adds the amount to the balance of the player. Rebuys are accepted during RebuyPeriod
+// from the start of the first hand, only between the hands the player takes part in and at most
+// MaxRebuys times per player.
+func (t *PokerTable) Rebuy(playerId string, amount int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, err := t.topUpPlayer(playerId, amount)
+	if err != nil {
+		return err
+	}
+	if t.Config.MaxRebuys > 0 && t.Meta.Rebuys[playerId] >= t.Config.MaxRebuys {
+		return ErrRebuyLimit
+	}
+	if amount < t.Config.MinBuyIn {
+		return ErrBuyInTooSmall
+	}
+	if t.Config.MaxBuyIn > 0 { // докупить можно только до максимального стека
+		amount = min(amount, t.Config.MaxBuyIn-p.GetBalance())
+		if amount <= 0 {
+			return ErrBuyInTooBig
+		}
+	}
+	if t.Meta.Rebuys == nil {
+		t.Meta.Rebuys = make(map[string]int)
+	}
+	t.Meta.Rebuys[playerId]++
+	p.ChangeBalance(amount)
+	t.NotifyObservers(Event{Kind: EventRebuy, PlayerID: playerId, Amount: amount})
+	return nil
+}
+
+// AddOn adds the amount to the balance of the player once per tournament. It is accepted
+// with AllowAddOn under the same conditions as Rebuy.
+func (t *PokerTable) AddOn(playerId string, amount int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.Config.AllowAddOn {
+		return ErrRebuyClosed
+	}
+	p, err := t.topUpPlayer(playerId, amount)
+	if err != nil {
+		return err
+	}
+	if t.Meta.AddOns[playerId] {
+		return ErrAddOnTaken
+	}
+	if t.Meta.AddOns == nil {
+		t.Meta.AddOns = make(map[string]bool)
+	}
+	t.Meta.AddOns[playerId] = true
+	p.ChangeBalance(amount)
+	t.NotifyObservers(Event{Kind: EventAddOn, PlayerID: playerId, Amount: amount})
+	return nil
+}
+
+// checkBuyIn checks the stack a player brings to the table against MinBuyIn and MaxBuyIn
+func (t *PokerTable) checkBuyIn(stack int) error {
+	if stack < t.Config.MinBuyIn {
+		return ErrBuyInTooSmall
+	}
+	if t.Config.MaxBuyIn > 0 && stack > t.Config.MaxBuyIn {
+		return ErrBuyInTooBig
+	}
+	return nil
+}
+
+// topUpPlayer returns the player who may buy more chips for the amount right now
+func (t *PokerTable) topUpPlayer(playerId string, amount int) (IPlayer, error) {
+	if t.Config.BankAmount <= 0 || !t.rebuyOpen() {
+		return nil, ErrRebuyClosed
+	}
+	if amount <= 0 {
+		return nil, ErrInvalidRebuy
+	}
+	p, ok := t.Meta.Players[playerId]
+	if ok && t.Meta.GameStarted && !p.GetFold() {
+		return nil, ErrGameStarted
+	}
+	if !ok {
+		if p, ok = t.Meta.Query[playerId]; !ok {
+			return nil, ErrPlayerNotFound
+		}
+	}
+	return p, nil
+}
+
+// ChipLeader returns the seated player with the biggest balance, the first in the seating on a tie
+func (t *PokerTable) ChipLeader() (string, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stackBy(func(a, b int) bool { return a > b })
+}
+
+// ShortStack returns the seated player with the smallest balance, the first in the seating on a tie
+func (t *PokerTable) ShortStack() (string, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stackBy(func(a, b int) bool { return a < b })
+}
+
+// stackBy returns the seated player whose balance beats all others by better
+func (t *PokerTable) stackBy(better func(a, b int) bool) (string, int) {
+	id, balance := "", 0
+	for _, k := range t.Meta.PlayersOrder {
+		b := t.Meta.Players[k].GetBalance()
+		if id == "" || better(b, balance) {
+			id, balance = k, b
+		}
+	}
+	return id, balance
+}
+
+// checkBadBeat notifies about every showdown loser whose hand is at least BadBeatThreshold
+// and pays him BadBeatPayout
+func (t *PokerTable) checkBadBeat() {
+	if t.Config.BadBeatThreshold == 0 {
+		return
+	}
+	inHand := make(map[string]IPlayer)
+	for k, v := range t.Meta.Players {
+		if !v.GetFold() {
+			inHand[k] = v
+		}
+	}
+	winners, err := determinateWinner(t.Meta.CommunityCards, inHand, t.evaluator())
+	if err != nil {
+		return
+	}
+	for _, k := range t.Meta.PlayersOrder {
+		p, ok := inHand[k]
+		if !ok || slices.Contains(winners, k) {
+			continue
+		}
+		hand := p.GetHand()
+		combination := t.evaluator()(hand.Cards, t.Meta.CommunityCards)
+		if combination.Rank < t.Config.BadBeatThreshold {
+			continue
+		}
+		t.NotifyObservers(Event{Kind: EventBadBeat, PlayerID: k, Rank: combination.Rank})
+		if t.Config.BadBeatPayout > 0 {
+			p.ChangeBalance(t.Config.BadBeatPayout)
+		}
+	}
+}
+
+// evaluator returns the hand evaluation of the variant played at the table
+func (t *PokerTable) evaluator() evaluator {
+	eval := evaluate
+	if t.Config.ShortDeck {
+		eval = evaluateShortDeck
+	}
+	if t.Config.Omaha {
+		return func(playerHand []Card, communityCards []Card) Combination {
+			return bestOmahaHand(playerHand, communityCards, eval)
+		}
+	}
+	return eval
+}
+
+// returnUncalledBet gives back to the aggressor the part of the biggest bet of the street
+// that nobody matched, so it never forms a pot of its own
+func (t *PokerTable) returnUncalledBet() {
+	top, topBet, second := "", 0, 0
+	for _, k := range t.Meta.PlayersOrder {
+		bet := t.Meta.Players[k].GetLastBet()
+		if bet > topBet {
+			top, topBet, second = k, bet, topBet
+		} else {
+			second = max(second, bet)
+		}
+	}
+	excess := topBet - second
+	if excess <= 0 {
+		return
+	}
+	p := t.Meta.Players[top]
+	p.SetLastBet(second)
+	p.ChangeBalance(excess)
+	p.SetAllIn(false)
+	t.NotifyObservers(Event{Kind: EventUncalledBet, PlayerID: top, Amount: excess})
+}
+
+func (t *PokerTable) createPots() error {
+	if !t.Meta.GameStarted {
+		return ErrGameNotStarted
+	}
+	t.returnUncalledBet()
+
+	pots := CreatePots(t.Meta.Players)
+	t.Meta.Pots = append(t.Meta.Pots, pots...)
+
+	return nil
+}
+
+// RemovePlayer removes the player from the table. A player removed during a hand folds,
+// the chips already bet stay in the pot.
+func (t *PokerTable) RemovePlayer(playerId string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.Meta.Players[playerId]; ok && t.Meta.GameStarted {
+		t.leaveHand(playerId)
+		return nil
+	}
+	return t.removePlayer(playerId)
+}
+
+// leaveHand folds the player who leaves the table in the middle of the hand and passes the turn on
+func (t *PokerTable) leaveHand(playerId string) {
+	p := t.Meta.Players[playerId]
+	hadTurn := t.Meta.PlayersOrder[t.Meta.PlayerTurnInd] == playerId
+	if !p.GetFold() {
+		p.SetFold(true)
+		t.NotifyObservers(Event{Kind: EventFold, PlayerID: playerId, Round: t.Meta.CurrentRound})
+	}
+	for i := range t.Meta.Pots { // игрока больше нет за столом, претендовать на банки он не может
+		t.Meta.Pots[i].Applicants = slices.DeleteFunc(slices.Clone(t.Meta.Pots[i].Applicants), func(k string) bool { return k == playerId })
+	}
+	if bet := p.GetLastBet(); bet > 0 { // ставка улицы остается в основном банке
+		if len(t.Meta.Pots) > 0 {
+			t.Meta.Pots[0].Amount += bet
+		} else {
+			inHand := slices.DeleteFunc(slices.Clone(t.Meta.PlayersOrder), func(k string) bool {
+				return k == playerId || t.Meta.Players[k].GetFold()
+			})
+			t.Meta.Pots = append(t.Meta.Pots, Pot{Amount: bet, Applicants: inHand})
+		}
+		p.SetLastBet(0)
+	}
+	t.removePlayer(playerId)
+
+	if t.playersInHand() == 1 {
+		t.winByFold()
+		return
+	}
+	if !hadTurn {
+		return
+	}
+	if !t.getNextPlayer() || t.checkReady() {
+		t.newRound()
+	} else {
+		t.notifyNext()
+	}
+}
+
+func (t *PokerTable) removePlayer(playerId string) error {
+	_, ok1 := t.Meta.Players[playerId]
+	_, ok2 := t.Meta.Query[playerId]
+	if !(ok1 || ok2) {
+		return ErrPlayerNotFound
+	}
+	delete(t.Meta.SittingOut, playerId)
+	if ok2 {
+		delete(t.Meta.Query, playerId)
+		t.Meta.QueryOrder = slices.DeleteFunc(t.Meta.QueryOrder, func(k string) bool { return k == playerId })
+		return nil
+	}
+	delete(t.Meta.Players, playerId)
+	delete(t.Meta.Disconnected, playerId)
+	ind := slices.Index(t.Meta.PlayersOrder, playerId)
+	t.Meta.PlayersOrder = append(t.Meta.PlayersOrder[:ind], t.Meta.PlayersOrder[ind+1:]...)
+	// индексы указывают на тех же игроков, место ушедшего занимает предыдущий игрок,
+	// поэтому ход и баттон переходят к следующему за ним
+	t.Meta.DealerIndex = shiftIndex(t.Meta.DealerIndex, ind, len(t.Meta.PlayersOrder))
+	t.Meta.PlayerTurnInd = shiftIndex(t.Meta.PlayerTurnInd, ind, len(t.Meta.PlayersOrder))
+	t.Meta.Blinds.SmallBlindIndex = shiftIndex(t.Meta.Blinds.SmallBlindIndex, ind, len(t.Meta.PlayersOrder))
+	t.Meta.Blinds.BigBlindIndex = shiftIndex(t.Meta.Blinds.BigBlindIndex, ind, len(t.Meta.PlayersOrder))
+	return nil
+}
+
+// shiftIndex returns the index in the order of size n after the seat removed was deleted
+func shiftIndex(index, removed, n int) int {
+	if n == 0 {
+		return 0
+	}
+	if removed <= index {
+		index--
+	}
+	return (index + n) % n
+}
+
+// ReserveSeat holds the seat of a player who stepped away for the given number of hands.
+// The player leaves the table, but nobody else can take the seat until the reservation expires
+// or the player comes back via AddPlayer.
+func (t *PokerTable) ReserveSeat(playerId string, hands int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hands <= 0 {
+		return ErrInvalidHands
+	}
+	_, inGame := t.Meta.Players[playerId]
+	if inGame && t.Meta.GameStarted {
+		return ErrGameStarted
+	}
+	if err := t.removePlayer(playerId); err != nil && err != ErrPlayerNotFound {
+		return err
+	}
+	t.Meta.Reservations[playerId] = hands
+	t.NotifyObservers(Event{Kind: EventSeatReserved, PlayerID: playerId, Amount: hands})
+	return nil
+}
+
+func (t *PokerTable) releaseReservations() {
+	for k, v := range t.Meta.Reservations {
+		if v > 1 {
+			t.Meta.Reservations[k] = v - 1
+			continue
+		}
+		delete(t.Meta.Reservations, k)
+		t.NotifyObservers(Event{Kind: EventReservationExpired, PlayerID: k})
+	}
+}
+
+func (t *PokerTable) betAnte() error {
+	if !t.Meta.GameStarted {
+		return ErrGameNotStarted
+	}
+	if t.Meta.CurrentRound != RoundPreflop || t.antePosted {
+		return ErrAnteNotAllowed
+	}
+	t.antePosted = true
+	if t.Config.AnteType == BigBlindAnte { // анте поставит большой блайнд в betBlinds
+		return nil
+	}
+	toRemove := []string{}
+	for k, v := range t.Meta.Players {
+		if t.Meta.SittingOut[k] {
+			continue
+		}
+		canPostPartial := t.Config.PartialAnte && v.GetBalance() > 0
+		if v.GetBalance() < t.Meta.Ante && !canPostPartial { // без PartialAnte короткий стек выбывает до раздачи
+			t.NotifyObservers(Event{Kind: EventAnteMissed, PlayerID: k})
+			toRemove = append(toRemove, k)
+		}
+	}
+	for _, id := range toRemove {
+		t.removePlayer(id)
+	}
+
+	total := 0
+	for _, k := range t.Meta.PlayersOrder {
+		if t.Meta.SittingOut[k] {
+			continue
+		}
+		p := t.Meta.Players[k]
+		ante := min(t.Meta.Ante, p.GetBalance())
+		p.ChangeBalance(-ante)
+		p.SetLastBet(ante)
+		total += ante
+		t.markAllIn(k)
+		if ante < t.Meta.Ante {
+			t.NotifyObservers(Event{Kind: EventPartialAnte, PlayerID: k, Amount: ante})
+		}
+	}
+	// короткие анте образуют побочные банки так же, как обычные ставки
+	t.Meta.Pots = append(t.Meta.Pots, CreatePots(t.Meta.Players)...)
+	t.NotifyObservers(Event{Kind: EventAnte, Amount: total})
+	return nil
+}
+
+func (t *PokerTable) betBlinds() error {
+	if !t.Meta.GameStarted {
+		return ErrGameNotStarted
+	}
+	smallBlindPlayer, bigBlindPlayer := t.Meta.Blinds.SmallBlind, t.Meta.Blinds.BigBlind
+	t.Meta.Straddle = ""
+
+	if t.Config.AnteType == BigBlindAnte && t.Meta.Ante > 0 {
+		t.betBigBlindAnte(bigBlindPlayer)
+	}
+
+	smallBlindPlayerBet := 0
+	if smallBlindPlayer != "" { // мертвый малый блайнд никто не ставит
+		smallBlindPlayerBet = min(t.Meta.SmallBlind, t.Meta.Players[smallBlindPlayer].GetBalance())
+		t.Meta.Players[smallBlindPlayer].ChangeBalance(-smallBlindPlayerBet)
+		t.Meta.Players[smallBlindPlayer].SetLastBet(smallBlindPlayerBet)
+		t.markAllIn(smallBlindPlayer)
+		t.NotifyObservers(Event{Kind: EventSmallBlind, PlayerID: smallBlindPlayer, Amount: smallBlindPlayerBet})
+	}
+
+	bigBlindPlayerBet := min(t.Meta.SmallBlind*2, t.Meta.Players[bigBlindPlayer].GetBalance())
+	t.Meta.Players[bigBlindPlayer].ChangeBalance(-bigBlindPlayerBet)
+	t.Meta.Players[bigBlindPlayer].SetLastBet(bigBlindPlayerBet)
+	t.markAllIn(bigBlindPlayer)
+	t.NotifyObservers(Event{Kind: EventBigBlind, PlayerID: bigBlindPlayer, Amount: bigBlindPlayerBet})
+	t.Meta.CurrentBet = max(bigBlindPlayerBet, smallBlindPlayerBet)
+	t.Meta.LastRaise = t.Meta.SmallBlind * 2
+	return nil
+}
+
+// PostStraddle posts a blind raise of double the big blind for the player left of the big blind.
+// It is allowed only before anybody acts preflop, the straddler acts last in the preflop.
+func (t *PokerTable) PostStraddle(playerId string, amount int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.Meta.GameStarted {
+		return ErrGameNotStarted
+	}
+	if t.Meta.Paused {
+		return ErrGamePaused
+	}
+	p, ok := t.Meta.Players[playerId]
+	if !ok {
+		return ErrPlayerNotFound
+	}
+	bigBlind := t.Meta.SmallBlind * 2
+	utg := t.nextSeated(t.Meta.Blinds.BigBlindIndex)
+	if t.Meta.CurrentRound != RoundPreflop || t.isHeadsUp() || t.Meta.Straddle != "" ||
+		t.Meta.PlayersOrder[utg] != playerId || t.Meta.PlayerTurnInd != utg || t.Meta.CurrentBet != bigBlind || p.GetReadyStatus() {
+		return ErrStraddleNotAllowed
+	}
+	if amount != bigBlind*2 {
+		return ErrInvalidStraddle
+	}
+	if amount-p.GetLastBet() > p.GetBalance() {
+		return ErrNotEnoughMoney
+	}
+	p.ChangeBalance(-(amount - p.GetLastBet()))
+	p.SetLastBet(amount)
+	t.Meta.CurrentBet = amount
+	t.Meta.LastRaise = amount // страддл играет роль большого блайнда
+	t.Meta.Straddle = playerId
+	t.markAllIn(playerId)
+	p.SetStatus(false) // страддл сохраняет право хода
+	t.NotifyObservers(Event{Kind: EventStraddle, PlayerID: playerId, Amount: amount})
+	t.choiceFirstMovePlayer()
+	t.notifyNext()
+	t.skipDisconnected()
+	return nil
+}
+
+// betBigBlindAnte posts the ante of the whole table from the big blind before the blind itself.
+// The ante is dead money of the main pot, a short big blind posts what they have.
+func (t *PokerTable) betBigBlindAnte(playerId string) {
+	p := t.Meta.Players[playerId]
+	ante := min(t.Meta.Ante, p.GetBalance())
+	p.ChangeBalance(-ante)
+	t.markAllIn(playerId)
+	t.Meta.Pots = append(t.Meta.Pots, Pot{Amount: ante, Applicants: slices.Clone(t.Meta.PlayersOrder)})
+	if ante < t.Meta.Ante {
+		t.NotifyObservers(Event{Kind: EventPartialAnte, PlayerID: playerId, Amount: ante})
+	}
+	t.NotifyObservers(Event{Kind: EventAnte, PlayerID: playerId, Amount: ante})
+}
+
+// LastRevealed returns the community cards opened on the current street:
+// three cards on the flop and one on the turn and the river
+func (t *PokerTable) LastRevealed() []Card {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.Meta.NewCommunityCards)
+}
+
+// AllInCoverage reports whether the all-in of the player can be matched by at least one opponent
+// still in the hand and how much of it nobody can call. It returns false and 0 if the player is not all-in.
+func (t *PokerTable) AllInCoverage(playerId string) (bool, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.allInCoverage(playerId)
+}
+
+func (t *PokerTable) allInCoverage(playerId string) (bool, int) {
+	p, ok := t.Meta.Players[playerId]
+	if !ok || !p.IsAllIn() {
+		return false, 0
+	}
+	maxCover := 0
+	for k, v := range t.Meta.Players {
+		if k == playerId || v.GetFold() {
+			continue
+		}
+		maxCover = max(maxCover, v.GetLastBet()+v.GetBalance())
+	}
+	uncalled := max(p.GetLastBet()-maxCover, 0)
+	return uncalled == 0, uncalled
+}
+
+// markAllIn flags the player as all-in once he has put his whole balance in
+func (t *PokerTable) markAllIn(playerId string) {
+	p := t.Meta.Players[playerId]
+	if p.GetBalance() == 0 && !p.GetFold() {
+		p.SetAllIn(true)
+		p.SetStatus(true)
+	}
+}
+
+// IsHeadsUp reports whether exactly two players remain in the hand
+func (t *PokerTable) IsHeadsUp() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.isHeadsUp()
+}
+
+func (t *PokerTable) isHeadsUp() bool {
+	return t.playersInHand() == 2
+}
+
+// getNextPlayer passes the turn to the next player who still has to act, the current player
+// is checked last. It reports false if nobody has to act and the street must be closed.
+func (t *PokerTable) getNextPlayer() bool {
+	for i := 1; i <= len(t.Meta.PlayersOrder); i++ {
+		nextIndex := (t.Meta.PlayerTurnInd + i) % len(t.Meta.PlayersOrder)
+		nextPlayer := t.Meta.PlayersOrder[nextIndex]
+		p := t.Meta.Players[nextPlayer]
+		if !p.GetFold() && !p.IsAllIn() && !p.GetReadyStatus() && p.GetBalance() > 0 {
+			t.Meta.PlayerTurnInd = nextIndex
+			t.NotifyObservers(Event{Kind: EventNextPlayer, PlayerID: nextPlayer})
+			return true
+		}
+	}
+	return false
+}
+
+// nextSeated returns the index of the first player after index who does not sit out
+func (t *PokerTable) nextSeated(index int) int {
+	n := len(t.Meta.PlayersOrder)
+	for i := 1; i <= n; i++ {
+		if next := (index + i) % n; !t.Meta.SittingOut[t.Meta.PlayersOrder[next]] {
+			return next
+		}
+	}
+	return (index + 1) % n
+}
+
+// dealOutSittingOut folds the sitting out players before the cards are dealt
+func (t *PokerTable) dealOutSittingOut() {
+	for k := range t.Meta.SittingOut {
+		if p, ok := t.Meta.Players[k]; ok {
+			p.SetHand(Hand{[]Card{}})
+			p.SetFold(true)
+			p.SetStatus(true)
 		}
 	}
 }
 
-func (t *PokerTable) createPots() error {
-	if !t.Meta.GameStarted {
-		return ErrGameNotStarted
+// SitOut keeps the seat of the player who skips the next hands. A player sitting out with
+// cards folds them at once unless already all-in.
+func (t *PokerTable) SitOut(playerId string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, seated := t.Meta.Players[playerId]
+	if _, queued := t.Meta.Query[playerId]; !seated && !queued {
+		return ErrPlayerNotFound
+	}
+	t.Meta.SittingOut[playerId] = true
+	t.NotifyObservers(Event{Kind: EventSatOut, PlayerID: playerId})
+	if !seated || !t.Meta.GameStarted || p.GetFold() || p.IsAllIn() {
+		return nil
+	}
+	if t.Meta.PlayersOrder[t.Meta.PlayerTurnInd] == playerId {
+		return t.makeMove(playerId, "fold", 0)
+	}
+	t.handleFold(playerId)
+	if t.playersInHand() == 1 {
+		t.winByFold()
 	}
-
-	pots := CreatePots(t.Meta.Players)
-	t.Meta.Pots = append(t.Meta.Pots, pots...)
-
 	return nil
 }
 
-func (t *PokerTable) RemovePlayer(playerId string) error {
-	_, ok1 := t.Meta.Players[playerId]
-	_, ok2 := t.Meta.Query[playerId]
-	if !(ok1 || ok2) {
+// SitIn returns the sitting out player to the game from the next hand
+func (t *PokerTable) SitIn(playerId string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.Meta.SittingOut[playerId] {
 		return ErrPlayerNotFound
 	}
-	if ok2 {
-		delete(t.Meta.Query, playerId)
-		return nil
-	}
-	delete(t.Meta.Players, playerId)
-	ind := slices.Index(t.Meta.PlayersOrder, playerId)
-	t.Meta.PlayersOrder = append(t.Meta.PlayersOrder[:ind], t.Meta.PlayersOrder[ind+1:]...)
+	delete(t.Meta.SittingOut, playerId)
+	t.NotifyObservers(Event{Kind: EventSatIn, PlayerID: playerId})
 	return nil
 }
 
-func (t *PokerTable) betAnte() error {
+func (t *PokerTable) choiceDealer() error {
 	if !t.Meta.GameStarted {
 		return ErrGameNotStarted
 	}
-	//TODO check if not 0 round
-	toRemove := []string{}
-	for k, v := range t.Meta.Players {
-		if v.GetBalance() < t.Meta.Ante {
-			v.GetFold()
-			t.NotifyObservers(fmt.Sprintf("Player %s cant bet ante", k))
-			toRemove = append(toRemove, k)
+	b := &t.Meta.Blinds
+	sb, bb := 0, 0
+	switch {
+	case b.BigBlind == "": // первая раздача, баттон просто переходит к следующему
+		t.Meta.DealerIndex = t.nextSeated(t.Meta.DealerIndex)
+		b.DeadButton = false
+		if t.isHeadsUp() { // дилер ставит малый блайнд в хендз апе
+			sb = t.Meta.DealerIndex
+		} else {
+			sb = t.nextSeated(t.Meta.DealerIndex)
+		}
+		bb = t.nextSeated(sb)
+		b.SmallBlind = t.Meta.PlayersOrder[sb]
+	case t.isHeadsUp():
+		bb = t.nextSeated(b.BigBlindIndex)
+		t.Meta.DealerIndex = t.nextSeated(bb)
+		sb = t.Meta.DealerIndex
+		b.DeadButton = false
+		b.SmallBlind = t.Meta.PlayersOrder[sb]
+	default:
+		// большой блайнд переходит к следующему, малый достается прошлому большому,
+		// баттон - прошлому малому; если их уже нет за столом, блайнд или баттон мертвые
+		bb = t.nextSeated(b.BigBlindIndex)
+		sb = b.BigBlindIndex
+		t.Meta.DealerIndex = b.SmallBlindIndex
+		b.DeadButton = !t.seatedAt(b.SmallBlindIndex, b.SmallBlind)
+		if !t.seatedAt(b.BigBlindIndex, b.BigBlind) {
+			b.SmallBlind = ""
+		} else {
+			b.SmallBlind = b.BigBlind
 		}
 	}
-	for _, id := range toRemove {
-		t.RemovePlayer(id)
+	b.SmallBlindIndex, b.BigBlindIndex = sb, bb
+	b.BigBlind = t.Meta.PlayersOrder[bb]
+	if b.DeadButton {
+		t.NotifyObservers(Event{Kind: EventDealer})
+		return nil
 	}
-
-	t.Meta.Pots = append(t.Meta.Pots, Pot{Amount: t.Meta.Ante * len(t.Meta.Players), Applicants: t.Meta.PlayersOrder})
-	t.NotifyObservers(fmt.Sprintf("Get ante: %d", t.Meta.Ante*len(t.Meta.Players)))
+	t.NotifyObservers(Event{Kind: EventDealer, PlayerID: t.Meta.PlayersOrder[t.Meta.DealerIndex]})
 	return nil
 }
 
-func (t *PokerTable) betBlinds() error {
+// CurrentPlayer returns the id of the player who has to act in the running hand
+func (t *PokerTable) CurrentPlayer() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if !t.Meta.GameStarted {
-		return ErrGameNotStarted
+		return "", ErrGameNotStarted
 	}
-	var smallBlindPlayer, bigBlindPlayer string
-	if len(t.Meta.PlayersOrder) > 2 {
-		smallBlindPlayer = t.Meta.PlayersOrder[(t.Meta.DealerIndex+1)%len(t.Meta.PlayersOrder)]
-		bigBlindPlayer = t.Meta.PlayersOrder[(t.Meta.DealerIndex+2)%len(t.Meta.PlayersOrder)]
-	} else {
-		smallBlindPlayer = t.Meta.PlayersOrder[t.Meta.DealerIndex] // дилер ставит малый блайнд в хендз апе
-		bigBlindPlayer = t.Meta.PlayersOrder[(t.Meta.DealerIndex+1)%len(t.Meta.PlayersOrder)]
+	return t.Meta.PlayersOrder[t.Meta.PlayerTurnInd], nil
+}
+
+// DealerID returns the player on the button of the current or the last hand,
+// empty before the first hand and when the button is dead
+func (t *PokerTable) DealerID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Meta.Blinds.BigBlind == "" || t.Meta.Blinds.DeadButton {
+		return ""
 	}
+	return t.Meta.PlayersOrder[t.Meta.DealerIndex]
+}
 
-	smallBlindPlayerBet := min(t.Meta.SmallBlind, t.Meta.Players[smallBlindPlayer].GetBalance())
-	t.Meta.Players[smallBlindPlayer].ChangeBalance(-smallBlindPlayerBet)
-	t.Meta.Players[smallBlindPlayer].SetLastBet(smallBlindPlayerBet)
-	t.NotifyObservers(fmt.Sprintf("Player %s bet %d as small blind", smallBlindPlayer, smallBlindPlayerBet))
+// SmallBlindID returns the player who posted the small blind of the current or the last hand.
+// In heads-up it is the dealer, empty if the small blind is dead.
+func (t *PokerTable) SmallBlindID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Meta.Blinds.SmallBlind
+}
 
-	bigBlindPlayerBet := min(t.Meta.SmallBlind*2, t.Meta.Players[bigBlindPlayer].GetBalance())
-	t.Meta.Players[bigBlindPlayer].ChangeBalance(-bigBlindPlayerBet)
-	t.Meta.Players[bigBlindPlayer].SetLastBet(bigBlindPlayerBet)
-	t.NotifyObservers(fmt.Sprintf("Player %s bet %d as big blind", bigBlindPlayer, bigBlindPlayerBet))
-	t.Meta.CurrentBet = max(bigBlindPlayerBet, smallBlindPlayerBet)
-	return nil
+// BigBlindID returns the player who posted the big blind of the current or the last hand
+func (t *PokerTable) BigBlindID() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Meta.Blinds.BigBlind
 }
 
-func (t *PokerTable) getNextPlayer() {
-	for i := 1; i < len(t.Meta.PlayersOrder); i++ {
-		nextIndex := (t.Meta.PlayerTurnInd + i) % len(t.Meta.PlayersOrder)
-		nextPlayer := t.Meta.PlayersOrder[nextIndex]
-		if !t.Meta.Players[nextPlayer].GetFold() && !t.Meta.Players[nextPlayer].GetReadyStatus() {
-			t.Meta.PlayerTurnInd = nextIndex
-			t.NotifyObservers(fmt.Sprintf("Next move expect from %s player", nextPlayer))
-			return
-		}
-	}
+// seatedAt reports whether the player still sits at the index and takes part in the hands
+func (t *PokerTable) seatedAt(index int, playerId string) bool {
+	return playerId != "" && index < len(t.Meta.PlayersOrder) && t.Meta.PlayersOrder[index] == playerId && !t.Meta.SittingOut[playerId]
 }
 
-func (t *PokerTable) choiceDealer() error {
-	if !t.Meta.GameStarted {
-		return ErrGameNotStarted
+// dealHoleCards deals the hole cards to every player. With GranularDealEvents the cards are dealt
+// one per player per pass and every card gets its own private DealtCard event with the card,
+// so only the observers of the owner see it.
+func (t *PokerTable) dealHoleCards() {
+	if !t.Config.GranularDealEvents {
+		for _, k := range t.Meta.PlayersOrder {
+			if t.Meta.SittingOut[k] {
+				continue
+			}
+			cards, _ := t.drawCard(t.Config.HoleCards)
+			t.Meta.Players[k].SetHand(Hand{cards})
+			t.NotifyObservers(Event{Kind: EventHoleCards, PlayerID: k, Cards: slices.Clone(cards)})
+		}
+		return
+	}
+	for _, k := range t.Meta.PlayersOrder {
+		t.Meta.Players[k].SetHand(Hand{[]Card{}})
+	}
+	for pass := 1; pass <= t.Config.HoleCards; pass++ {
+		for _, k := range t.Meta.PlayersOrder {
+			if t.Meta.SittingOut[k] {
+				continue
+			}
+			cards, err := t.drawCard(1)
+			if err != nil {
+				return
+			}
+			p := t.Meta.Players[k]
+			p.SetHand(Hand{append(p.GetHand().Cards, cards...)})
+			t.NotifyObservers(Event{Kind: EventDealtCard, PlayerID: k, Index: pass, Cards: slices.Clone(cards)})
+		}
 	}
-	t.Meta.DealerIndex = (t.Meta.DealerIndex + 1) % len(t.Meta.PlayersOrder)
-	t.NotifyObservers(fmt.Sprintf("dealer is %s", t.Meta.PlayersOrder[t.Meta.DealerIndex]))
-	return nil
 }
 
 func (t *PokerTable) drawCard(n int) ([]Card, error) {
@@ -373,22 +2159,113 @@ func (t *PokerTable) drawCard(n int) ([]Card, error) {
 	return output, nil
 }
 
+// RabbitHunt returns the community cards that would have been dealt if the finished hand had
+// gone to the river. The cards are only shown, the deck and the results stay untouched.
+func (t *PokerTable) RabbitHunt() ([]Card, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.Meta.GameStarted {
+		return nil, ErrGameStarted
+	}
+	if len(t.Meta.CommunityCards) >= 5 {
+		return nil, ErrBoardComplete
+	}
+
+	deck := t.Meta.Deck
+	cards := []Card{}
+	for len(t.Meta.CommunityCards)+len(cards) < 5 {
+		deal := 1
+		if len(t.Meta.CommunityCards)+len(cards) == 0 { // флоп открывается тремя картами
+			deal = 3
+		}
+		burn := t.burnCount()
+		if len(deck) < burn+deal {
+			return nil, ErrNotEnoughCards
+		}
+		cards = append(cards, deck[burn:burn+deal]...)
+		deck = deck[burn+deal:]
+	}
+	return cards, nil
+}
+
+// burnCount returns how many cards are burned before every community street
+func (t *PokerTable) burnCount() int {
+	if t.Config.BurnCount > 0 {
+		return t.Config.BurnCount
+	}
+	if t.Config.BurnCards {
+		return 1
+	}
+	return 0
+}
+
+// checkDeck reports ErrNotEnoughCards if the deck can't cover the hole cards of every player,
+// the five community cards and the burns before the flop, the turn and the river
+func (t *PokerTable) checkDeck() error {
+	players := len(t.Meta.PlayersOrder) + len(t.Meta.Query)
+	boards := 1
+	if t.Config.RunItTwice {
+		boards = 2
+	}
+	if players*t.Config.HoleCards+boards*(5+3*t.burnCount()) > len(t.Meta.Deck) {
+		return ErrNotEnoughCards
+	}
+	return nil
+}
+
+// burnCard discards the top cards of the deck before a community street if burning is enabled
+func (t *PokerTable) burnCard() error {
+	if t.burnCount() == 0 {
+		return nil
+	}
+	cards, err := t.drawCard(t.burnCount())
+	if err != nil {
+		return err
+	}
+	t.Meta.Discards = append(t.Meta.Discards, cards...)
+	return nil
+}
+
 func (t *PokerTable) choiceFirstMovePlayer() error {
 	if !t.Meta.GameStarted {
 		return ErrGameNotStarted
 	}
-	if t.Meta.CurrentRound == 0 { //utg
-		t.Meta.PlayerTurnInd = (t.Meta.DealerIndex + 3) % len(t.Meta.PlayersOrder)
-	} else {
-		t.Meta.PlayerTurnInd = (t.Meta.DealerIndex + 1) % len(t.Meta.PlayersOrder)
+	if t.Meta.CurrentRound == RoundPreflop && t.isHeadsUp() { // в хендз апе префлоп начинает дилер (малый блайнд)
+		t.Meta.PlayerTurnInd = t.Meta.DealerIndex
+	} else if t.Meta.CurrentRound == RoundPreflop && t.Meta.Straddle != "" { // после страддла ходит следующий за ним
+		t.Meta.PlayerTurnInd = t.nextSeated(slices.Index(t.Meta.PlayersOrder, t.Meta.Straddle))
+	} else if t.Meta.CurrentRound == RoundPreflop { //utg
+		t.Meta.PlayerTurnInd = t.nextSeated(t.Meta.Blinds.BigBlindIndex)
+	} else { // после флопа первым ходит следующий за дилером, в хендз апе это большой блайнд
+		t.Meta.PlayerTurnInd = t.nextSeated(t.Meta.DealerIndex)
+	}
+	if p := t.Meta.Players[t.Meta.PlayersOrder[t.Meta.PlayerTurnInd]]; p.GetFold() || p.IsAllIn() {
+		t.getNextPlayer()
 	}
 	return nil
 }
 
 func (t *PokerTable) MakeMove(playerId, action string, amount int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.makeMove(playerId, action, amount); err != nil {
+		return err
+	}
+	t.skipDisconnected()
+	return nil
+}
+
+func (t *PokerTable) makeMove(playerId, action string, amount int) error {
 	if !t.Meta.GameStarted {
 		return ErrGameNotStarted
 	}
+	if t.Meta.Paused {
+		return ErrGamePaused
+	}
+
+	if p, ok := t.Meta.Players[playerId]; ok && p.IsAllIn() {
+		return ErrPlayerAllIn
+	}
 
 	if t.Meta.PlayersOrder[t.Meta.PlayerTurnInd] != playerId {
 		return ErrNotYourTurn
@@ -398,38 +2275,199 @@ func (t *PokerTable) MakeMove(playerId, action string, amount int) error {
 		return ErrPlayerIsFold
 	}
 
+	var err error
 	switch action {
 	case "check":
-		t.handleCheck(playerId)
+		err = t.handleCheck(playerId)
+	case "bet":
+		err = t.handleBet(playerId, amount)
 	case "raise":
-		t.handleRaise(playerId, amount)
+		err = t.handleRaise(playerId, amount)
 	case "call":
-		t.handleCall(playerId)
+		err = t.handleCall(playerId)
 	case "fold":
-		t.handleFold(playerId)
+		err = t.handleFold(playerId)
+	case "allin":
+		err = t.handleAllIn(playerId)
 	default:
 		return ErrUnexpectedAction
 	}
+	if err != nil {
+		return err
+	}
+	t.recordMove(playerId, action, amount)
 	t.Meta.Players[playerId].SetStatus(true)
-	t.getNextPlayer()
-	if t.checkReady() {
-		t.NewRound()
+	if t.playersInHand() == 1 {
+		t.winByFold()
+		return nil
+	}
+	if !t.getNextPlayer() || t.checkReady() { // ходить больше некому, улица закрыта
+		if !t.holdRunOut() {
+			t.newRound()
+		}
 	} else {
 		t.notifyNext()
 	}
 	return nil
 }
 
+// Disconnect marks the player as disconnected. A disconnected player acts automatically
+// (check if possible, otherwise fold) as soon as the turn reaches him.
+func (t *PokerTable) Disconnect(playerId string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.Meta.Players[playerId]; !ok {
+		return ErrPlayerNotFound
+	}
+	t.Meta.Disconnected[playerId] = true
+	t.NotifyObservers(Event{Kind: EventDisconnected, PlayerID: playerId})
+	t.skipDisconnected()
+	return nil
+}
+
+func (t *PokerTable) Reconnect(playerId string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.Meta.Disconnected[playerId]; !ok {
+		return ErrPlayerNotFound
+	}
+	delete(t.Meta.Disconnected, playerId)
+	t.NotifyObservers(Event{Kind: EventReconnected, PlayerID: playerId})
+	return nil
+}
+
+// autoAction returns the move made for a player who can't act himself
+func (t *PokerTable) autoAction(playerId string) string {
+	if t.Meta.CurrentBet == t.Meta.Players[playerId].GetLastBet() {
+		return "check"
+	}
+	return "fold"
+}
+
+// skipDisconnected makes moves for all disconnected players standing in a row in one pass
+// and starts the move timer of the player who acts next
+func (t *PokerTable) skipDisconnected() {
+	for t.Meta.GameStarted {
+		pId := t.Meta.PlayersOrder[t.Meta.PlayerTurnInd]
+		if !t.Meta.Disconnected[pId] {
+			break
+		}
+		action := t.autoAction(pId)
+		t.NotifyObservers(Event{Kind: EventAutoMove, PlayerID: pId, Action: action})
+		if err := t.makeMove(pId, action, 0); err != nil {
+			break
+		}
+	}
+	t.startMoveTimer()
+}
+
+// startMoveTimer cancels the timer of the previous move and starts a new one for the current player
+func (t *PokerTable) startMoveTimer() {
+	t.restartMoveTimer(t.Config.MoveTimeout)
+}
+
+// restartMoveTimer is startMoveTimer that gives the current player only d to move
+func (t *PokerTable) restartMoveTimer(d time.Duration) {
+	if t.stopTimer != nil {
+		t.stopTimer()
+		t.stopTimer = nil
+	}
+	t.moveSeq++
+	t.chargeTimeBank()
+	t.moveDeadline = time.Time{}
+	if t.Config.MoveTimeout <= 0 || !t.Meta.GameStarted || t.Meta.Paused {
+		return
+	}
+	seq, pId := t.moveSeq, t.Meta.PlayersOrder[t.Meta.PlayerTurnInd]
+	t.moveDeadline = t.now().Add(d)
+	t.stopTimer = t.timerOrDefault().AfterFunc(d, func() { t.moveTimeout(seq, pId) })
+}
+
+func (t *PokerTable) timerOrDefault() Timer {
+	if t.timer == nil {
+		return systemTimer{}
+	}
+	return t.timer
+}
+
+// chargeTimeBank takes the time spent from the bank of the player who moved while using it
+func (t *PokerTable) chargeTimeBank() {
+	if t.bankStart.IsZero() {
+		return
+	}
+	used := t.now().Sub(t.bankStart)
+	t.Meta.TimeBanks[t.bankUser] = max(t.Meta.TimeBanks[t.bankUser]-used, 0)
+	t.bankUser, t.bankStart = "", time.Time{}
+}
+
+// refillTimeBanks gives newcomers the full time bank and tops up the others by TimeBankRefill
+func (t *PokerTable) refillTimeBanks() {
+	if t.Config.TimeBank <= 0 {
+		return
+	}
+	for _, k := range t.Meta.PlayersOrder {
+		bank, ok := t.Meta.TimeBanks[k]
+		if !ok {
+			bank = t.Config.TimeBank
+		} else {
+			bank = min(bank+t.Config.TimeBankRefill, t.Config.TimeBank)
+		}
+		t.Meta.TimeBanks[k] = bank
+	}
+}
+
+// moveTimeout makes the automatic move for the player who has not acted in time.
+// A player with a time bank gets the whole bank as an extra timer first.
+func (t *PokerTable) moveTimeout(seq int, playerId string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if seq != t.moveSeq { // игрок успел походить, пока таймер ждал блокировку
+		return
+	}
+	t.moveDeadline = time.Time{}
+	if t.Meta.RunOutPending { // на страховку дается время одного хода, потом борд открывается сам
+		t.resumeRunOut()
+		t.skipDisconnected()
+		return
+	}
+	if bank := t.Meta.TimeBanks[playerId]; t.bankStart.IsZero() && bank > 0 {
+		t.bankUser, t.bankStart = playerId, t.now()
+		t.NotifyObservers(Event{Kind: EventTimeBank, PlayerID: playerId, Duration: bank})
+		t.stopTimer = t.timerOrDefault().AfterFunc(bank, func() { t.moveTimeout(seq, playerId) })
+		return
+	}
+	if !t.bankStart.IsZero() { // банк кончился целиком
+		t.Meta.TimeBanks[playerId] = 0
+		t.bankUser, t.bankStart = "", time.Time{}
+	}
+	action := t.autoAction(playerId)
+	t.NotifyObservers(Event{Kind: EventTimeout, PlayerID: playerId, Action: action})
+	if err := t.makeMove(playerId, action, 0); err != nil {
+		return
+	}
+	t.skipDisconnected()
+}
+
 func (t *PokerTable) notifyNext() error {
 	if !t.Meta.GameStarted {
 		return ErrGameNotStarted
 	}
 	pId := t.Meta.PlayersOrder[t.Meta.PlayerTurnInd]
-	if t.Meta.CurrentBet != 0 {
-		t.NotifyObservers(fmt.Sprintf("player %s can do call with %d", pId, t.Meta.CurrentBet))
-	} else {
-		t.NotifyObservers(fmt.Sprintf("player %s can do check", pId))
+	p := t.Meta.Players[pId]
+	minRaise := t.minRaise()
+	if t.Meta.CurrentBet == 0 {
+		minRaise = t.minBet()
 	}
+	maxRaise := t.maxRaise(pId)
+	t.NotifyObservers(Event{
+		Kind:     EventTurn,
+		PlayerID: pId,
+		Amount:   max(t.Meta.CurrentBet-p.GetLastBet(), 0), // сколько доложить до колла
+		Round:    t.Meta.CurrentRound,
+		Pot:      t.totalPot(),
+		MinRaise: min(minRaise, maxRaise), // стек меньше минимума - остается только олл-ин
+		MaxRaise: maxRaise,
+	})
 	return nil
 }
 
@@ -438,7 +2476,11 @@ func (t *PokerTable) checkReady() bool {
 		return false
 	}
 	for _, v := range t.Meta.Players {
-		if (!v.GetFold() && !v.GetReadyStatus()) || v.GetBalance() == 0 {
+		// без фишек игроку больше нечем ходить, недостающее до ставки решают побочные банки
+		if v.IsAllIn() || v.GetFold() || v.GetBalance() == 0 {
+			continue
+		}
+		if !v.GetReadyStatus() {
 			return false
 		}
 	}
@@ -453,11 +2495,11 @@ func (t *PokerTable) handleCheck(playerId string) error {
 		return ErrPlayerIsFold
 	}
 
-	if t.Meta.CurrentBet != 0 {
+	if t.Meta.CurrentBet != t.Meta.Players[playerId].GetLastBet() { // большой блайнд может чекнуть на префлопе
 		return ErrCantCheck
 	}
 	t.Meta.Players[playerId].SetStatus(true)
-	t.NotifyObservers(fmt.Sprintf("Player %s do check", playerId))
+	t.NotifyObservers(Event{Kind: EventCheck, PlayerID: playerId, Round: t.Meta.CurrentRound})
 	return nil
 }
 
@@ -468,7 +2510,7 @@ func (t *PokerTable) handleFold(playerId string) error {
 
 	t.Meta.Players[playerId].SetStatus(true)
 	t.Meta.Players[playerId].SetFold(true)
-	t.NotifyObservers(fmt.Sprintf("Player %s do fold", playerId))
+	t.NotifyObservers(Event{Kind: EventFold, PlayerID: playerId, Round: t.Meta.CurrentRound})
 	return nil
 }
 
@@ -479,8 +2521,85 @@ func (t *PokerTable) handleRaise(playerId string, amount int) error {
 	if t.Meta.Players[playerId].GetFold() {
 		return ErrPlayerIsFold
 	}
-	if !(amount > t.Meta.CurrentBet*2 && amount > t.Meta.Players[playerId].GetLastBet() && amount > 0) {
-		return ErrCantRaise
+	if t.Meta.CurrentBet == 0 { // ставок на улице еще не было, рейз открывает торги как bet
+		return t.handleBet(playerId, amount)
+	}
+	if amount < t.minRaise() {
+		return fmt.Errorf("%w: minimum raise is %d", ErrRaiseTooSmall, t.minRaise())
+	}
+	if err := t.checkLimit(playerId, amount); err != nil {
+		return err
+	}
+	delta := amount - t.Meta.Players[playerId].GetLastBet()
+	if delta > t.Meta.Players[playerId].GetBalance() {
+		return ErrNotEnoughMoney
+	}
+	t.resetPlayersStatus()
+	t.Meta.Players[playerId].SetLastBet(amount)
+	t.Meta.Players[playerId].ChangeBalance(-delta)
+	t.Meta.Players[playerId].SetStatus(true)
+	t.Meta.LastRaise = amount - t.Meta.CurrentBet
+	t.Meta.CurrentBet = amount
+	t.Meta.StreetRaises++
+	t.Meta.LastAggressor = playerId
+	t.markAllIn(playerId)
+
+	t.NotifyObservers(Event{Kind: EventRaise, PlayerID: playerId, Amount: amount, Round: t.Meta.CurrentRound})
+	return nil
+}
+
+// handleAllIn pushes the whole balance of the player. If the all-in exceeds the current bet
+// it becomes the new bet and the other players have to act again.
+func (t *PokerTable) handleAllIn(playerId string) error {
+	if !t.Meta.GameStarted {
+		return ErrGameNotStarted
+	}
+	p := t.Meta.Players[playerId]
+	if p.GetFold() {
+		return ErrPlayerIsFold
+	}
+	if p.GetBalance() == 0 {
+		return ErrNotEnoughMoney
+	}
+	amount := p.GetLastBet() + p.GetBalance()
+	if err := t.checkLimit(playerId, amount); err != nil {
+		return err
+	}
+	p.ChangeBalance(-p.GetBalance())
+	p.SetLastBet(amount)
+	p.SetAllIn(true)
+	if amount > t.Meta.CurrentBet {
+		t.resetPlayersStatus()
+		if amount >= t.minRaise() { // олл-ин меньше минимального рейза не меняет его размер
+			t.Meta.LastRaise = amount - t.Meta.CurrentBet
+		}
+		if t.Meta.CurrentBet > 0 {
+			t.Meta.StreetRaises++
+		}
+		t.Meta.CurrentBet = amount
+		t.Meta.LastAggressor = playerId
+	}
+	p.SetStatus(true)
+
+	t.NotifyObservers(Event{Kind: EventAllIn, PlayerID: playerId, Amount: amount, Round: t.Meta.CurrentRound})
+	return nil
+}
+
+func (t *PokerTable) handleBet(playerId string, amount int) error {
+	if !t.Meta.GameStarted {
+		return ErrGameNotStarted
+	}
+	if t.Meta.Players[playerId].GetFold() {
+		return ErrPlayerIsFold
+	}
+	if t.Meta.CurrentBet != 0 {
+		return ErrCantBet
+	}
+	if amount < t.minBet() {
+		return ErrBetTooSmall
+	}
+	if err := t.checkLimit(playerId, amount); err != nil {
+		return err
 	}
 	delta := amount - t.Meta.Players[playerId].GetLastBet()
 	if delta > t.Meta.Players[playerId].GetBalance() {
@@ -491,8 +2610,136 @@ func (t *PokerTable) handleRaise(playerId string, amount int) error {
 	t.Meta.Players[playerId].ChangeBalance(-delta)
 	t.Meta.Players[playerId].SetStatus(true)
 	t.Meta.CurrentBet = amount
+	t.Meta.LastRaise = amount
+	t.Meta.LastAggressor = playerId
+	t.markAllIn(playerId)
+
+	t.NotifyObservers(Event{Kind: EventBet, PlayerID: playerId, Amount: amount, Round: t.Meta.CurrentRound})
+	return nil
+}
+
+// RaiseToAllIn returns the raise amount for the player to act that puts the target all-in:
+// the target's remaining stack plus the chips he already bet on this street, raised to the
+// minimum raise and clamped to what the acting player can bet. If a call already covers the
+// target it returns ErrTargetCovered, if the acting player can't make the minimum raise
+// ErrNotEnoughMoney: only the all-in is left.
+func (t *PokerTable) RaiseToAllIn(targetPlayerId string) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.raiseToAllIn(targetPlayerId)
+}
+
+func (t *PokerTable) raiseToAllIn(targetPlayerId string) (int, error) {
+	if !t.Meta.GameStarted {
+		return 0, ErrGameNotStarted
+	}
+	target, ok := t.Meta.Players[targetPlayerId]
+	if !ok {
+		return 0, ErrPlayerNotFound
+	}
+	if target.GetFold() {
+		return 0, ErrPlayerIsFold
+	}
+	amount := target.GetBalance() + target.GetLastBet()
+	if amount <= t.Meta.CurrentBet {
+		return 0, ErrTargetCovered
+	}
+	minimum := t.minRaise()
+	if t.Meta.CurrentBet == 0 {
+		minimum = t.minBet()
+	}
+	// меньше минимума повысить нельзя, а цель окажется в олл-ине и от большего рейза
+	amount = min(max(amount, minimum), t.maxRaise(t.Meta.PlayersOrder[t.Meta.PlayerTurnInd]))
+	if amount < minimum {
+		return 0, fmt.Errorf("%w: minimum raise is %d", ErrNotEnoughMoney, minimum)
+	}
+	return amount, nil
+}
+
+// minBet returns the minimum opening bet on the current street, the big blind by default
+func (t *PokerTable) minBet() int {
+	if t.Config.LimitType == FixedLimit {
+		return t.fixedIncrement()
+	}
+	if bet, ok := t.Config.MinBetByStreet[Street(t.Meta.CurrentRound)]; ok {
+		return bet
+	}
+	return t.Meta.SmallBlind * 2
+}
+
+// minRaise returns the smallest legal raise: the current bet plus the size of the last raise,
+// but not less than the big blind
+func (t *PokerTable) minRaise() int {
+	if t.Config.LimitType == FixedLimit {
+		return t.Meta.CurrentBet + t.fixedIncrement()
+	}
+	return t.Meta.CurrentBet + max(t.Meta.LastRaise, t.Meta.SmallBlind*2)
+}
+
+// potLimit returns the largest raise in pot-limit: the current bet plus the pot after the call
+func (t *PokerTable) potLimit(playerId string) int {
+	call := max(t.Meta.CurrentBet-t.Meta.Players[playerId].GetLastBet(), 0)
+	return t.Meta.CurrentBet + t.totalPot() + call
+}
+
+// maxRaise returns the largest amount the player may bet or raise to
+func (t *PokerTable) maxRaise(playerId string) int {
+	p := t.Meta.Players[playerId]
+	stack := p.GetBalance() + p.GetLastBet()
+	switch t.Config.LimitType {
+	case PotLimit:
+		return min(stack, t.potLimit(playerId))
+	case FixedLimit:
+		if t.raiseCapReached() {
+			return min(stack, t.Meta.CurrentBet)
+		}
+		return min(stack, t.Meta.CurrentBet+t.fixedIncrement())
+	}
+	return stack
+}
+
+// fixedIncrement returns the size of a bet or raise in fixed-limit on the current street
+func (t *PokerTable) fixedIncrement() int {
+	smallBet := t.Config.SmallBet
+	if smallBet == 0 {
+		smallBet = t.Meta.SmallBlind * 2
+	}
+	if Street(t.Meta.CurrentRound) < Turn {
+		return smallBet
+	}
+	if t.Config.BigBet == 0 {
+		return smallBet * 2
+	}
+	return t.Config.BigBet
+}
 
-	t.NotifyObservers(fmt.Sprintf("Player %s do raise with %d amount", playerId, amount))
+// raiseCapReached reports whether no more raises are allowed on the current street in fixed-limit
+func (t *PokerTable) raiseCapReached() bool {
+	raiseCap := t.Config.RaiseCap
+	if raiseCap == 0 {
+		raiseCap = defaultRaiseCap
+	}
+	return t.Meta.CurrentBet > 0 && t.Meta.StreetRaises >= raiseCap
+}
+
+// checkLimit validates the bet or raise amount against the betting limit of the table
+func (t *PokerTable) checkLimit(playerId string, amount int) error {
+	if amount <= t.Meta.CurrentBet { // колл олл-ином лимит не ограничивает
+		return nil
+	}
+	switch t.Config.LimitType {
+	case PotLimit:
+		if limit := t.potLimit(playerId); amount > limit {
+			return fmt.Errorf("%w: maximum is %d", ErrRaiseTooBig, limit)
+		}
+	case FixedLimit:
+		if t.raiseCapReached() {
+			return ErrRaiseCapReached
+		}
+		if limit := t.Meta.CurrentBet + t.fixedIncrement(); amount > limit {
+			return fmt.Errorf("%w: maximum is %d", ErrRaiseTooBig, limit)
+		}
+	}
 	return nil
 }
 
@@ -502,7 +2749,7 @@ func (t *PokerTable) resetPlayersStatus() error {
 	}
 
 	for k, v := range t.Meta.Players {
-		if !v.GetFold() {
+		if !v.GetFold() && !v.IsAllIn() {
 			t.Meta.Players[k].SetStatus(false)
 		}
 	}
@@ -513,9 +2760,10 @@ func refreshPlayers(players map[string]IPlayer, fold bool) error {
 	for _, v := range players {
 		if fold {
 			v.SetFold(false)
+			v.SetAllIn(false)
 		}
 		v.SetLastBet(0)
-		v.SetStatus(false)
+		v.SetStatus(v.IsAllIn()) // игроку в олл-ине ходить больше не нужно
 	}
 	return nil
 }
@@ -536,12 +2784,9 @@ func (t *PokerTable) handleCall(playerId string) error {
 
 	t.Meta.Players[playerId].ChangeBalance(-possibleBet)
 	t.Meta.Players[playerId].SetStatus(true)
-	if t.Meta.Players[playerId].GetBalance() > 0 {
-		t.Meta.Players[playerId].SetLastBet(t.Meta.CurrentBet)
-	} else {
-		t.Meta.Players[playerId].SetLastBet(possibleBet)
-	}
+	t.Meta.Players[playerId].SetLastBet(t.Meta.Players[playerId].GetLastBet() + possibleBet)
+	t.markAllIn(playerId)
 
-	t.NotifyObservers(fmt.Sprintf("Player %s do call with %d amount", playerId, t.Meta.CurrentBet))
+	t.NotifyObservers(Event{Kind: EventCall, PlayerID: playerId, Amount: t.Meta.CurrentBet, Round: t.Meta.CurrentRound})
 	return nil
 }
@@ -1,7 +1,11 @@
 package holdem
 
 import (
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -55,6 +59,7 @@ func TestTableGame2Good(t *testing.T) {
 	t.Run("game 2", func(t *testing.T) {
 		meta := NewTableMeta(50, 0, 1488)
 		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		config.Deterministic = true // вторая раздача рассчитана на колоду из сида первой
 		table := NewPokerTable(config, meta)
 		p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000} //bb
 		p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
@@ -107,3 +112,2907 @@ func TestTableGame2Good(t *testing.T) {
 		require.Equal(t, table.Meta.GameStarted, false)
 	})
 }
+
+// playHand доигрывает текущую раздачу, отвечая call (или check) за каждого игрока
+func playHand(t *testing.T, table *PokerTable) {
+	t.Helper()
+	for i := 0; table.Meta.GameStarted; i++ {
+		require.Less(t, i, 100, "hand did not finish")
+		pId := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+		require.NoError(t, table.MakeMove(pId, "call", 0))
+	}
+}
+
+func TestReserveSeat(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 3, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	p4 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000004"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.Equal(t, ErrInvalidHands, table.ReserveSeat(p3.GetId(), 0))
+	require.NoError(t, table.ReserveSeat(p3.GetId(), 2))
+	require.NotContains(t, table.Meta.PlayersOrder, p3.GetId())
+	require.Equal(t, ErrMaxPlayers, table.AddPlayer(p4))
+
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+	require.Equal(t, 1, table.Meta.Reservations[p3.GetId()])
+	require.Equal(t, ErrMaxPlayers, table.AddPlayer(p4))
+
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+	require.NotContains(t, table.Meta.Reservations, p3.GetId())
+	require.NoError(t, table.AddPlayer(p4))
+}
+
+func TestReserveSeatReturningPlayer(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 3, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.NoError(t, table.ReserveSeat(p3.GetId(), 3))
+	require.NoError(t, table.AddPlayer(p3))
+	require.Empty(t, table.Meta.Reservations)
+	require.Contains(t, table.Meta.PlayersOrder, p3.GetId())
+}
+
+func TestBlindIncreaseMinHandsPerLevel(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.MinHandsPerLevel = 2
+	config.LastBlindIncrease = time.Now().Add(-2 * time.Hour)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 50, table.Meta.SmallBlind)
+	playHand(t, table)
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 50, table.Meta.SmallBlind)
+	playHand(t, table)
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 100, table.Meta.SmallBlind)
+	require.Equal(t, 0, table.Meta.HandsAtLevel)
+	playHand(t, table)
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 100, table.Meta.SmallBlind)
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// fakeTimer keeps the last started timer until the test fires it
+type fakeTimer struct {
+	d time.Duration
+	f func()
+}
+
+func (ft *fakeTimer) AfterFunc(d time.Duration, f func()) func() bool {
+	ft.d, ft.f = d, f
+	return func() bool {
+		ft.f = nil
+		return true
+	}
+}
+
+func (ft *fakeTimer) Fire() {
+	if f := ft.f; f != nil {
+		ft.f = nil
+		f()
+	}
+}
+
+func TestBlindSchedule(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(10*time.Minute, 10, 2, -1, false)
+	config.LastBlindIncrease = clock.Now()
+	config.BlindLevels = []BlindLevel{{SmallBlind: 50}, {SmallBlind: 100, Ante: 10}, {SmallBlind: 200, Ante: 25}}
+	table := NewPokerTable(config, meta)
+	table.SetClock(clock)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 10000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 10000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	levels := []struct {
+		Advance    time.Duration
+		SmallBlind int
+		Ante       int
+	}{
+		{Advance: 0, SmallBlind: 50, Ante: 0},
+		{Advance: 11 * time.Minute, SmallBlind: 100, Ante: 10},
+		{Advance: 5 * time.Minute, SmallBlind: 100, Ante: 10},
+		{Advance: 6 * time.Minute, SmallBlind: 200, Ante: 25},
+		{Advance: 20 * time.Minute, SmallBlind: 200, Ante: 25}, // расписание закончилось
+	}
+	for i, level := range levels {
+		clock.Advance(level.Advance)
+		require.NoError(t, table.StartGame())
+		require.Equal(t, level.SmallBlind, table.Meta.SmallBlind, "hand %d", i+1)
+		require.Equal(t, level.Ante, table.Meta.Ante, "hand %d", i+1)
+		playHand(t, table)
+	}
+}
+
+func TestPotAndStackInBB(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 1.5, table.PotInBB())             // 50 + 100 блайнды
+	require.Equal(t, 9.5, table.StackInBB(p3.GetId())) // малый блайнд
+	require.Equal(t, 9.0, table.StackInBB(p1.GetId())) // большой блайнд
+	require.Equal(t, 0.0, table.StackInBB("unknown"))
+
+	table.MakeMove(p2.GetId(), "call", 0)
+	table.MakeMove(p3.GetId(), "call", 0)
+	table.MakeMove(p1.GetId(), "call", 0)
+	require.Equal(t, 3.0, table.PotInBB())
+
+	table.Meta.SmallBlind = 100
+	require.Equal(t, 1.5, table.PotInBB())
+	require.Equal(t, 4.5, table.StackInBB(p1.GetId()))
+}
+
+func TestDisconnectedPlayersInARow(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	players := make([]*Player, 0, 5)
+	for i := 1; i <= 5; i++ {
+		p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000}
+		require.NoError(t, table.AddPlayer(p))
+		players = append(players, p)
+	}
+	// дилер p2, малый блайнд p3, большой блайнд p4, первым ходит p5
+	for _, p := range players[:3] {
+		require.NoError(t, table.Disconnect(p.GetId()))
+	}
+	require.Equal(t, ErrPlayerNotFound, table.Disconnect("unknown"))
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, players[4].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+	require.NoError(t, table.MakeMove(players[4].GetId(), "call", 0))
+
+	require.Equal(t, players[3].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+	for _, p := range players[:3] {
+		require.True(t, p.GetFold())
+	}
+	require.Equal(t, 950, players[2].GetBalance())
+
+	require.NoError(t, table.Reconnect(players[0].GetId()))
+	require.Equal(t, ErrPlayerNotFound, table.Reconnect(players[0].GetId()))
+}
+
+func TestMinBetByStreet(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.MinBetByStreet = map[Street]int{Turn: 400, River: 400}
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+
+	for table.Meta.CurrentRound < int(Flop) {
+		pId := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+		require.NoError(t, table.MakeMove(pId, "call", 0))
+	}
+	pId := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	require.Equal(t, ErrBetTooSmall, table.MakeMove(pId, "raise", 50))
+	require.NoError(t, table.MakeMove(pId, "raise", 100)) // на флопе минимум - большой блайнд
+	pId = table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	require.NoError(t, table.MakeMove(pId, "call", 0))
+
+	require.Equal(t, int(Turn), table.Meta.CurrentRound)
+	pId = table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	require.Equal(t, ErrBetTooSmall, table.MakeMove(pId, "raise", 300))
+	require.Equal(t, 0, table.Meta.CurrentBet)
+	require.NoError(t, table.MakeMove(pId, "raise", 400))
+	require.Equal(t, 400, table.Meta.CurrentBet)
+}
+
+func TestIsHeadsUp(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.NoError(t, table.StartGame())
+	require.False(t, table.IsHeadsUp())
+	require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+	require.True(t, table.IsHeadsUp())
+	playHand(t, table)
+
+	require.NoError(t, table.RemovePlayer(p3.GetId()))
+	p1Balance, p2Balance := p1.GetBalance(), p2.GetBalance()
+	require.NoError(t, table.StartGame())
+	require.True(t, table.IsHeadsUp())
+	// в хендз апе дилер ставит малый блайнд и первым ходит на префлопе
+	require.Equal(t, p1.GetId(), table.Meta.PlayersOrder[table.Meta.DealerIndex])
+	require.Equal(t, p1Balance-50, p1.GetBalance())
+	require.Equal(t, p2Balance-100, p2.GetBalance())
+	require.Equal(t, p1.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	// после флопа первым ходит большой блайнд
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+	require.Equal(t, p2.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+}
+
+type eventRecorder struct {
+	events []string
+	raw    []Event
+}
+
+func (r *eventRecorder) Update(event Event) {
+	r.events = append(r.events, event.String())
+	r.raw = append(r.raw, event)
+}
+
+func TestBadBeatJackpot(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.BadBeatThreshold = FourOfAKind
+	config.BadBeatPayout = 5000
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+
+	quads := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 0,
+		Hand: Hand{[]Card{{Suit: "Diamonds", Value: 9}, {Suit: "Clubs", Value: 9}}}}
+	straightFlush := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 0,
+		Hand: Hand{[]Card{{Suit: "Spades", Value: 12}, {Suit: "Spades", Value: 13}}}}
+	meta.Players = map[string]IPlayer{quads.GetId(): quads, straightFlush.GetId(): straightFlush}
+	meta.PlayersOrder = []string{quads.GetId(), straightFlush.GetId()}
+	meta.CommunityCards = []Card{
+		{Suit: "Spades", Value: 9}, {Suit: "Hearts", Value: 9}, {Suit: "Spades", Value: 10},
+		{Suit: "Spades", Value: 11}, {Suit: "Diamonds", Value: 2},
+	}
+	meta.Pots = []Pot{{Amount: 1000, Applicants: []string{quads.GetId(), straightFlush.GetId()}}}
+
+	table.PayMoney()
+	require.Equal(t, 1000, straightFlush.GetBalance())
+	require.Equal(t, 5000, quads.GetBalance())
+	require.Contains(t, recorder.events, fmt.Sprintf("BadBeatJackpot: player %s lost with combination %s", quads.GetId(), FourOfAKind))
+}
+
+func TestLastRevealed(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+	require.Empty(t, table.LastRevealed())
+
+	expected := map[int]int{int(Flop): 3, int(Turn): 1, int(River): 1}
+	for round := int(Flop); round <= int(River); round++ {
+		for table.Meta.CurrentRound < round {
+			pId := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+			require.NoError(t, table.MakeMove(pId, "call", 0))
+		}
+		revealed := table.LastRevealed()
+		require.Len(t, revealed, expected[round])
+		require.Equal(t, table.Meta.CommunityCards[len(table.Meta.CommunityCards)-len(revealed):], revealed)
+		revealed[0] = Card{} // возвращается копия, стол не меняется
+		require.NotEqual(t, Card{}, table.Meta.NewCommunityCards[0])
+	}
+}
+
+func TestBetAnte(t *testing.T) {
+	cases := []struct {
+		TestCaseName     string
+		PartialAnte      bool
+		ShortBalance     int
+		ExpectedOrder    int
+		ExpectedBalances []int
+		ExpectedPots     []Pot
+	}{
+		{
+			TestCaseName:     "Everybody pays",
+			PartialAnte:      false,
+			ShortBalance:     1000,
+			ExpectedOrder:    3,
+			ExpectedBalances: []int{990, 990, 990},
+			ExpectedPots: []Pot{
+				{Amount: 30, Applicants: []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000003"}},
+			},
+		},
+		{
+			TestCaseName:     "Short player removed",
+			PartialAnte:      false,
+			ShortBalance:     6,
+			ExpectedOrder:    2,
+			ExpectedBalances: []int{990, 990, 6},
+			ExpectedPots: []Pot{
+				{Amount: 20, Applicants: []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"}},
+			},
+		},
+		{
+			TestCaseName:     "Short player posts partial ante",
+			PartialAnte:      true,
+			ShortBalance:     6,
+			ExpectedOrder:    3,
+			ExpectedBalances: []int{990, 990, 0},
+			ExpectedPots: []Pot{
+				{Amount: 18, Applicants: []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002", "00000000-0000-0000-0000-000000000003"}},
+				{Amount: 8, Applicants: []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"}},
+			},
+		},
+	}
+	for _, tCase := range cases {
+		t.Run(tCase.TestCaseName, func(t *testing.T) {
+			meta := NewTableMeta(50, 10, 1488)
+			config := NewTableConfig(time.Hour, 10, 2, -1, false)
+			config.PartialAnte = tCase.PartialAnte
+			table := NewPokerTable(config, meta)
+			p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+			p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+			p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: tCase.ShortBalance}
+			require.NoError(t, table.AddPlayer(p1))
+			require.NoError(t, table.AddPlayer(p2))
+			require.NoError(t, table.AddPlayer(p3))
+			meta.GameStarted = true
+			meta.CurrentRound = int(PreFlop)
+
+			require.NoError(t, table.betAnte())
+			require.Len(t, meta.PlayersOrder, tCase.ExpectedOrder)
+			require.Equal(t, tCase.ExpectedBalances, []int{p1.GetBalance(), p2.GetBalance(), p3.GetBalance()})
+			require.Len(t, meta.Pots, len(tCase.ExpectedPots))
+			for k := range meta.Pots {
+				require.ElementsMatch(t, tCase.ExpectedPots[k].Applicants, meta.Pots[k].Applicants)
+				require.Equal(t, tCase.ExpectedPots[k].Amount, meta.Pots[k].Amount)
+			}
+		})
+	}
+}
+
+func TestRaiseToAllIn(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 600}  //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 5000} //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	_, err := table.RaiseToAllIn(p1.GetId())
+	require.Equal(t, ErrGameNotStarted, err)
+	require.NoError(t, table.StartGame())
+
+	_, err = table.RaiseToAllIn("unknown")
+	require.Equal(t, ErrPlayerNotFound, err)
+
+	amount, err := table.RaiseToAllIn(p3.GetId())
+	require.NoError(t, err)
+	require.Equal(t, 1000, amount) // ограничено стеком p2
+
+	amount, err = table.RaiseToAllIn(p1.GetId())
+	require.NoError(t, err)
+	require.Equal(t, 600, amount) // 500 в стеке и 100 большой блайнд
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", amount))
+	require.Equal(t, 600, table.Meta.CurrentBet)
+	require.Equal(t, p1.GetBalance()+p1.GetLastBet(), table.Meta.CurrentBet)
+
+	_, err = table.RaiseToAllIn(p1.GetId()) // p3 достаточно колла
+	require.ErrorIs(t, err, ErrTargetCovered)
+}
+
+func TestRaiseToAllInMinRaise(t *testing.T) {
+	tests := []struct {
+		name           string
+		bb, dealer, sb int
+		target         int // 1 - p1, 3 - p3
+		want           int
+		err            error
+	}{
+		{"Short target gets the minimum raise", 150, 1000, 1000, 1, 200, nil}, // 50 в стеке и 100 большой блайнд
+		{"Actor can't make the minimum raise", 1000, 150, 1000, 3, 0, ErrNotEnoughMoney},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := NewTableMeta(50, 0, 1488)
+			config := NewTableConfig(time.Hour, 10, 2, -1, false)
+			table := NewPokerTable(config, meta)
+			p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: tt.bb}
+			p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: tt.dealer}
+			p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: tt.sb}
+			require.NoError(t, table.AddPlayer(p1))
+			require.NoError(t, table.AddPlayer(p2))
+			require.NoError(t, table.AddPlayer(p3))
+			require.NoError(t, table.StartGame())
+
+			target := []*Player{p1, p2, p3}[tt.target-1]
+			amount, err := table.RaiseToAllIn(target.GetId())
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.want, amount)
+			if tt.err != nil {
+				return
+			}
+			require.NoError(t, table.MakeMove(p2.GetId(), "raise", amount))
+			require.Greater(t, table.Meta.CurrentBet, target.GetBalance()+target.GetLastBet())
+		})
+	}
+}
+
+func TestShowdownMargin(t *testing.T) {
+	board := []Card{
+		{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 13}, {Suit: "Clubs", Value: 9},
+		{Suit: "Diamonds", Value: 7}, {Suit: "Spades", Value: 2},
+	}
+	cases := []struct {
+		TestCaseName    string
+		FirstHand       Hand
+		SecondHand      Hand
+		ExpectedWinners int
+		PositiveMargin  bool
+	}{
+		{
+			TestCaseName:    "Split",
+			FirstHand:       Hand{[]Card{{Suit: "Hearts", Value: 3}, {Suit: "Clubs", Value: 4}}},
+			SecondHand:      Hand{[]Card{{Suit: "Diamonds", Value: 3}, {Suit: "Spades", Value: 4}}},
+			ExpectedWinners: 2,
+			PositiveMargin:  false,
+		},
+		{
+			TestCaseName:    "Clear winner",
+			FirstHand:       Hand{[]Card{{Suit: "Hearts", Value: 3}, {Suit: "Clubs", Value: 4}}},
+			SecondHand:      Hand{[]Card{{Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 12}}},
+			ExpectedWinners: 1,
+			PositiveMargin:  true,
+		},
+	}
+	for _, tCase := range cases {
+		t.Run(tCase.TestCaseName, func(t *testing.T) {
+			table := NewPokerTable(NewTableConfig(time.Hour, 10, 2, -1, false), NewTableMeta(50, 0, 1488))
+			p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Hand: tCase.FirstHand}
+			p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Hand: tCase.SecondHand}
+			table.Meta.Players = map[string]IPlayer{p1.GetId(): p1, p2.GetId(): p2}
+			table.Meta.PlayersOrder = []string{p1.GetId(), p2.GetId()}
+			table.Meta.CommunityCards = board
+			table.Meta.Pots = []Pot{{Amount: 200, Applicants: []string{p1.GetId(), p2.GetId()}}}
+
+			table.PayMoney()
+			require.Len(t, table.Meta.ShowdownResults, 1)
+			res := table.Meta.ShowdownResults[0]
+			require.Len(t, res.Winners, tCase.ExpectedWinners)
+			require.Equal(t, tCase.PositiveMargin, res.Margin > 0)
+			require.GreaterOrEqual(t, res.Margin, 0)
+		})
+	}
+}
+
+func TestBetAndRaiseOnFlop(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000} //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000} //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	require.Equal(t, ErrCantBet, table.MakeMove(p2.GetId(), "bet", 200))
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+
+	require.Equal(t, ErrBetTooSmall, table.MakeMove(p3.GetId(), "bet", 50))
+	require.Equal(t, ErrNotEnoughMoney, table.MakeMove(p3.GetId(), "bet", 1000))
+	require.NoError(t, table.MakeMove(p3.GetId(), "bet", 150))
+	require.Equal(t, 150, table.Meta.CurrentBet)
+	require.Equal(t, 750, p3.GetBalance())
+
+	require.Equal(t, ErrCantBet, table.MakeMove(p1.GetId(), "bet", 300))
+	require.NoError(t, table.MakeMove(p1.GetId(), "raise", 400))
+	require.Equal(t, 400, table.Meta.CurrentBet)
+	require.False(t, p3.GetReadyStatus())
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.Equal(t, int(Turn), table.Meta.CurrentRound)
+	require.Equal(t, []int{500, 500, 500}, []int{p1.GetBalance(), p2.GetBalance(), p3.GetBalance()})
+}
+
+func TestMinRaise(t *testing.T) {
+	newTable := func() (*PokerTable, *Player, *Player, *Player) {
+		meta := NewTableMeta(50, 0, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		table := NewPokerTable(config, meta)
+		p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 2000} //bb
+		p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 2000} //dealer
+		p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 2000} //sb
+		require.NoError(t, table.AddPlayer(p1))
+		require.NoError(t, table.AddPlayer(p2))
+		require.NoError(t, table.AddPlayer(p3))
+		require.NoError(t, table.StartGame())
+		return table, p1, p2, p3
+	}
+
+	t.Run("preflop", func(t *testing.T) {
+		table, _, p2, _ := newTable()
+		err := table.MakeMove(p2.GetId(), "raise", 199)
+		require.ErrorIs(t, err, ErrRaiseTooSmall)
+		require.ErrorContains(t, err, "200")
+		require.NoError(t, table.MakeMove(p2.GetId(), "raise", 200))
+		require.Equal(t, 100, table.Meta.LastRaise)
+	})
+
+	t.Run("postflop", func(t *testing.T) {
+		table, p1, p2, p3 := newTable()
+		require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+		require.Equal(t, int(Flop), table.Meta.CurrentRound)
+
+		require.NoError(t, table.MakeMove(p3.GetId(), "bet", 150))
+		err := table.MakeMove(p1.GetId(), "raise", 250)
+		require.ErrorIs(t, err, ErrRaiseTooSmall)
+		require.ErrorContains(t, err, "300")
+		require.NoError(t, table.MakeMove(p1.GetId(), "raise", 300))
+	})
+
+	t.Run("re-raise", func(t *testing.T) {
+		table, p1, p2, p3 := newTable()
+		require.NoError(t, table.MakeMove(p2.GetId(), "raise", 350)) // рейз на 250
+		err := table.MakeMove(p3.GetId(), "raise", 550)
+		require.ErrorIs(t, err, ErrRaiseTooSmall)
+		require.ErrorContains(t, err, "600")
+		require.NoError(t, table.MakeMove(p3.GetId(), "raise", 600))
+		require.Equal(t, 250, table.Meta.LastRaise)
+
+		require.NoError(t, table.MakeMove(p1.GetId(), "raise", 1000)) // рейз на 400
+		require.ErrorIs(t, table.MakeMove(p2.GetId(), "raise", 1399), ErrRaiseTooSmall)
+		require.NoError(t, table.MakeMove(p2.GetId(), "raise", 1400))
+	})
+}
+
+type reverseShuffler struct {
+	err error
+}
+
+func (s reverseShuffler) Shuffle(deck []Card) ([]Card, []byte, error) {
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	slices.Reverse(deck)
+	return deck, []byte("proof-1"), nil
+}
+
+func TestExternalShuffler(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.Shuffler = reverseShuffler{}
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, []byte("proof-1"), table.Meta.ShuffleProof)
+	require.Equal(t, Hand{[]Card{{Suit: "Clubs", Value: 14}, {Suit: "Diamonds", Value: 14}}}, p1.GetHand())
+	require.Equal(t, Hand{[]Card{{Suit: "Hearts", Value: 14}, {Suit: "Spades", Value: 14}}}, p2.GetHand())
+	playHand(t, table)
+
+	shuffleErr := errors.New("beacon unavailable")
+	config.Shuffler = reverseShuffler{err: shuffleErr}
+	require.Equal(t, shuffleErr, table.StartGame())
+	require.False(t, table.Meta.GameStarted)
+}
+
+func TestAllIn(t *testing.T) {
+	newTable := func() (*PokerTable, *Player, *Player, *Player) {
+		meta := NewTableMeta(50, 0, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		table := NewPokerTable(config, meta)
+		p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000} //bb
+		p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+		p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000} //sb
+		require.NoError(t, table.AddPlayer(p1))
+		require.NoError(t, table.AddPlayer(p2))
+		require.NoError(t, table.AddPlayer(p3))
+		require.NoError(t, table.StartGame())
+		return table, p1, p2, p3
+	}
+
+	t.Run("short all-in does not reopen betting", func(t *testing.T) {
+		table, p1, p2, p3 := newTable()
+		require.NoError(t, table.MakeMove(p2.GetId(), "raise", 500))
+		p3.Balance = 150 // 200 вместе с малым блайндом, меньше текущей ставки
+		require.NoError(t, table.MakeMove(p3.GetId(), "allin", 0))
+		require.True(t, p3.IsAllIn())
+		require.Equal(t, 200, p3.GetLastBet())
+		require.Equal(t, 500, table.Meta.CurrentBet)
+		require.True(t, p2.GetReadyStatus())
+
+		require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+		require.Equal(t, 1, table.Meta.CurrentRound)
+		require.True(t, p3.IsAllIn())
+		require.NotEqual(t, p3.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+	})
+
+	t.Run("all-in above the bet reopens betting", func(t *testing.T) {
+		table, p1, p2, p3 := newTable()
+		require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p3.GetId(), "allin", 0))
+		require.Equal(t, 1000, table.Meta.CurrentBet)
+		require.Equal(t, 0, p3.GetBalance())
+		require.False(t, p2.GetReadyStatus())
+		require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+		require.Equal(t, 0, table.Meta.CurrentRound)
+		require.Equal(t, p2.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+		require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+		// оба оставшихся игрока в олл-ине, раздача доигрывается без ходов
+		require.False(t, table.Meta.GameStarted)
+		require.Equal(t, 900, p2.GetBalance())
+		require.Equal(t, 2100, p1.GetBalance()+p3.GetBalance()) // колл сбросившего p2 остается в банке
+		require.False(t, p1.IsAllIn())
+		require.False(t, p3.IsAllIn())
+	})
+}
+
+func TestZeroHoleCards(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.HoleCards = 0
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+	for _, p := range []*Player{p1, p2, p3} {
+		require.Empty(t, p.GetHand().Cards)
+	}
+
+	playHand(t, table)
+	require.Len(t, table.Meta.CommunityCards, 5)
+	require.Len(t, table.Meta.ShowdownResults, 1)
+	require.ElementsMatch(t, []string{p1.GetId(), p2.GetId(), p3.GetId()}, table.Meta.ShowdownResults[0].Winners)
+	require.Equal(t, []int{1000, 1000, 1000}, []int{p1.GetBalance(), p2.GetBalance(), p3.GetBalance()})
+
+	config.HoleCards = -1
+	require.Equal(t, ErrInvalidHoleCards, table.StartGame())
+}
+
+func TestPotLimit(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.LimitType = PotLimit
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 20000} //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 20000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 20000} //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	// банк 150, колл 100: рейз до 100 + 250
+	err := table.MakeMove(p2.GetId(), "raise", 351)
+	require.ErrorIs(t, err, ErrRaiseTooBig)
+	require.ErrorContains(t, err, "350")
+	require.Equal(t, ErrRaiseTooBig, errors.Unwrap(table.MakeMove(p2.GetId(), "allin", 0)))
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 350))
+
+	// банк 500, колл 300: рейз до 350 + 800
+	require.ErrorIs(t, table.MakeMove(p3.GetId(), "raise", 1151), ErrRaiseTooBig)
+	require.NoError(t, table.MakeMove(p3.GetId(), "raise", 1150))
+
+	// банк 1600, колл 1050: рейз до 1150 + 2650
+	require.ErrorIs(t, table.MakeMove(p1.GetId(), "raise", 3801), ErrRaiseTooBig)
+	require.NoError(t, table.MakeMove(p1.GetId(), "raise", 3800))
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+
+	// на флопе ставка ограничена банком 11400, олл-ин недоступен
+	options, _ := table.AllMoveOptions()
+	require.Equal(t, []MoveOption{
+		{Action: "fold"},
+		{Action: "check"},
+		{Action: "bet", Min: 100, Max: 11400},
+	}, options[p3.GetId()])
+	require.NoError(t, table.MakeMove(p3.GetId(), "bet", 3000))
+
+	// лимит 3000 + 14400 + 3000 больше стека 16200
+	_, _, maxAmount, err := table.LegalActions(p1.GetId())
+	require.NoError(t, err)
+	require.Equal(t, 16200, maxAmount)
+	require.NoError(t, table.MakeMove(p1.GetId(), "allin", 0))
+}
+
+func TestFixedLimit(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.LimitType = FixedLimit
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 5000} //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 5000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 5000} //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	require.ErrorIs(t, table.MakeMove(p2.GetId(), "raise", 300), ErrRaiseTooBig)
+	require.ErrorIs(t, table.MakeMove(p2.GetId(), "allin", 0), ErrRaiseTooBig)
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 200))
+	require.NoError(t, table.MakeMove(p3.GetId(), "raise", 300))
+	require.NoError(t, table.MakeMove(p1.GetId(), "raise", 400))
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 500))
+	require.Equal(t, ErrRaiseCapReached, table.MakeMove(p3.GetId(), "raise", 600))
+	_, _, maxAmount, err := table.LegalActions(p3.GetId())
+	require.NoError(t, err)
+	require.Equal(t, 0, maxAmount)
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+	require.Equal(t, 0, table.Meta.StreetRaises)
+
+	// на флопе шаг по-прежнему 100
+	require.ErrorIs(t, table.MakeMove(p3.GetId(), "bet", 200), ErrRaiseTooBig)
+	require.NoError(t, table.MakeMove(p3.GetId(), "bet", 100))
+	require.NoError(t, table.MakeMove(p1.GetId(), "raise", 200))
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.Equal(t, int(Turn), table.Meta.CurrentRound)
+
+	// на терне шаг удваивается
+	require.Equal(t, ErrBetTooSmall, table.MakeMove(p3.GetId(), "bet", 100))
+	require.NoError(t, table.MakeMove(p3.GetId(), "bet", 200))
+	require.ErrorIs(t, table.MakeMove(p1.GetId(), "raise", 300), ErrRaiseTooSmall)
+	require.NoError(t, table.MakeMove(p1.GetId(), "raise", 400))
+}
+
+func TestPendingEntrants(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, true)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	p4 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000004"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+	require.Empty(t, table.PendingEntrants())
+
+	require.NoError(t, table.AddPlayer(p4))
+	require.NoError(t, table.AddPlayer(p3))
+	require.Equal(t, []string{p4.GetId(), p3.GetId()}, table.PendingEntrants())
+
+	playHand(t, table)
+	require.NoError(t, table.StartGame())
+	require.Equal(t, []string{p1.GetId(), p2.GetId(), p4.GetId(), p3.GetId()}, table.Meta.PlayersOrder)
+	require.Empty(t, table.PendingEntrants())
+	require.Empty(t, table.Meta.Query)
+}
+
+func TestConcurrentMoves(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 20, 2, -1, true)
+	table := NewPokerTable(config, meta)
+	players := []*Player{}
+	for i := 1; i <= 4; i++ {
+		p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i)), Balance: 1000}
+		players = append(players, p)
+		require.NoError(t, table.AddPlayer(p))
+	}
+	require.NoError(t, table.StartGame())
+
+	var wg sync.WaitGroup
+	for _, p := range players {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			for { // каждый игрок пытается сходить, пока раздача не закончится
+				if err := table.MakeMove(id, "call", 0); err == ErrGameNotStarted {
+					return
+				}
+			}
+		}(p.GetId())
+	}
+	for i := 5; i <= 10; i++ {
+		wg.Add(1)
+		go func(id uuid.UUID) {
+			defer wg.Done()
+			table.AddPlayer(&Player{Id: id, Balance: 1000})
+		}(uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i)))
+	}
+	wg.Wait()
+
+	require.False(t, table.Meta.GameStarted)
+	require.Len(t, table.Meta.PlayersOrder, len(table.Meta.Players))
+	require.Equal(t, 10, len(table.Meta.Players)+len(table.Meta.Query))
+	total := 0
+	for _, p := range players {
+		total += p.GetBalance()
+	}
+	require.Equal(t, 4000, total)
+}
+
+func TestZeroSeedShuffle(t *testing.T) {
+	meta := NewTableMeta(50, 0, 0)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	require.NoError(t, table.AddPlayer(&Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}))
+	require.NoError(t, table.AddPlayer(&Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}))
+	require.NotPanics(t, func() { require.NoError(t, table.StartGame()) })
+
+	dealt := slices.Concat(table.Meta.Players["00000000-0000-0000-0000-000000000001"].GetHand().Cards,
+		table.Meta.Players["00000000-0000-0000-0000-000000000002"].GetHand().Cards, table.Meta.Deck)
+	require.ElementsMatch(t, GetStandardDeck(), dealt)
+	require.NotEqual(t, GetStandardDeck()[4:], table.Meta.Deck)
+}
+
+func TestSetSeating(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.Equal(t, ErrInvalidSeating, table.SetSeating([]string{p3.GetId(), p1.GetId()}))
+	require.Equal(t, ErrInvalidSeating, table.SetSeating([]string{p3.GetId(), p1.GetId(), p1.GetId()}))
+	require.Equal(t, ErrInvalidSeating, table.SetSeating([]string{p3.GetId(), p1.GetId(), "unknown"}))
+	require.NoError(t, table.SetSeating([]string{p3.GetId(), p1.GetId(), p2.GetId()}))
+	require.NoError(t, table.StartGame())
+
+	// дилер p1, малый блайнд p2, большой p3, первым ходит дилер
+	require.Equal(t, p1.GetId(), table.Meta.PlayersOrder[table.Meta.DealerIndex])
+	require.Equal(t, 50, p2.GetLastBet())
+	require.Equal(t, 100, p3.GetLastBet())
+	require.Equal(t, p1.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+	require.Equal(t, ErrGameStarted, table.SetSeating([]string{p1.GetId(), p2.GetId(), p3.GetId()}))
+}
+
+type noopShuffler struct{}
+
+func (noopShuffler) Shuffle(int, func(i, j int)) {}
+
+func TestSetRand(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	table.SetRand(noopShuffler{})
+	require.NoError(t, table.StartGame())
+
+	// колода не перемешана, карты раздаются по порядку
+	deck := GetStandardDeck()
+	require.Equal(t, deck[0:2], p1.GetHand().Cards)
+	require.Equal(t, deck[2:4], p2.GetHand().Cards)
+	require.Equal(t, deck[4:], table.Meta.Deck)
+}
+
+func TestCallBreakdown(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 200}  //utg
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 500}  //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 5000} //sb
+	p4 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000004"), Balance: 2000} //bb
+	for _, p := range []*Player{p1, p2, p3, p4} {
+		require.NoError(t, table.AddPlayer(p))
+	}
+	require.NoError(t, table.StartGame())
+	require.Nil(t, table.CallBreakdown(p4.GetId()))
+
+	require.NoError(t, table.MakeMove(p1.GetId(), "allin", 0))
+	require.NoError(t, table.MakeMove(p2.GetId(), "allin", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "raise", 1000))
+
+	breakdown := table.CallBreakdown(p4.GetId())
+	require.Equal(t, []PotContribution{
+		{Amount: 100, Applicants: []string{p1.GetId(), p2.GetId(), p3.GetId(), p4.GetId()}},
+		{Amount: 300, Applicants: []string{p2.GetId(), p3.GetId(), p4.GetId()}},
+		{Amount: 500, Applicants: []string{p3.GetId(), p4.GetId()}},
+	}, breakdown)
+	total := 0
+	for _, c := range breakdown {
+		total += c.Amount
+	}
+	require.Equal(t, table.Meta.CurrentBet-p4.GetLastBet(), total)
+}
+
+func TestBurnCards(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.BurnCards = true
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	table.SetRand(noopShuffler{})
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+
+	// 4 карты ушли игрокам, дальше сжигание перед каждой улицей
+	deck := GetStandardDeck()
+	require.Equal(t, []Card{deck[4], deck[8], deck[10]}, table.Meta.Discards)
+	require.Equal(t, []Card{deck[5], deck[6], deck[7], deck[9], deck[11]}, table.Meta.CommunityCards)
+	require.Equal(t, deck[12:], table.Meta.Deck)
+}
+
+func TestBurnCount(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.BurnCount = 2
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	table.SetRand(noopShuffler{})
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+
+	// по две сожженные карты перед флопом, терном и ривером
+	deck := GetStandardDeck()
+	require.Equal(t, []Card{deck[4], deck[5], deck[9], deck[10], deck[12], deck[13]}, table.Meta.Discards)
+	require.Equal(t, []Card{deck[6], deck[7], deck[8], deck[11], deck[14]}, table.Meta.CommunityCards)
+
+	// 22 игрока: 44 карты на руки, 5 на борд и 6 сжечь - колоды не хватает
+	config.HoleCards = 2
+	config.BurnCount = 2
+	config.MaxPlayers = 30
+	for i := 3; i <= 22; i++ {
+		require.NoError(t, table.AddPlayer(&Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i)), Balance: 1000}))
+	}
+	require.Equal(t, ErrNotEnoughCards, table.StartGame())
+	config.BurnCount = 1 // 44 + 5 + 3 = 52
+	require.NoError(t, table.StartGame())
+}
+
+func TestRabbitHunt(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.BurnCards = true
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	table.SetRand(noopShuffler{})
+	require.NoError(t, table.StartGame())
+
+	_, err := table.RabbitHunt()
+	require.Equal(t, ErrGameStarted, err)
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+	require.NoError(t, table.MakeMove(p1.GetId(), "bet", 100))
+	require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+	require.False(t, table.Meta.GameStarted)
+
+	deck := GetStandardDeck()
+	cards, err := table.RabbitHunt()
+	require.NoError(t, err)
+	require.Equal(t, []Card{deck[9], deck[11]}, cards) // терн и ривер после сжигания
+	require.Equal(t, deck[8:], table.Meta.Deck)
+}
+
+func TestWinByFold(t *testing.T) {
+	newTable := func() (*PokerTable, *Player, *Player, *Player, *eventRecorder) {
+		meta := NewTableMeta(50, 0, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		table := NewPokerTable(config, meta)
+		p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000} //bb
+		p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+		p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000} //sb
+		require.NoError(t, table.AddPlayer(p1))
+		require.NoError(t, table.AddPlayer(p2))
+		require.NoError(t, table.AddPlayer(p3))
+		recorder := &eventRecorder{}
+		table.AddObserver(recorder)
+		require.NoError(t, table.StartGame())
+		return table, p1, p2, p3, recorder
+	}
+
+	t.Run("preflop walk", func(t *testing.T) {
+		table, p1, p2, p3, recorder := newTable()
+		require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+		require.NoError(t, table.MakeMove(p3.GetId(), "fold", 0))
+		require.False(t, table.Meta.GameStarted)
+		require.Empty(t, table.Meta.CommunityCards)
+		require.Equal(t, []int{1050, 1000, 950}, []int{p1.GetBalance(), p2.GetBalance(), p3.GetBalance()})
+		// несравненная половина большого блайнда возвращается до выплаты
+		require.Contains(t, recorder.events, fmt.Sprintf("Uncalled bet of 50 amount returned to player %s", p1.GetId()))
+		require.Contains(t, recorder.events, fmt.Sprintf("Hand ended by fold, player %s wins 100 amount", p1.GetId()))
+		require.False(t, p2.GetFold())
+	})
+
+	t.Run("two of three fold on the flop", func(t *testing.T) {
+		table, p1, p2, p3, _ := newTable()
+		require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+		require.NoError(t, table.MakeMove(p3.GetId(), "bet", 200))
+		require.NoError(t, table.MakeMove(p1.GetId(), "fold", 0))
+		require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+		require.False(t, table.Meta.GameStarted)
+		require.Len(t, table.Meta.CommunityCards, 3)
+		require.Equal(t, []int{900, 900, 1200}, []int{p1.GetBalance(), p2.GetBalance(), p3.GetBalance()})
+	})
+}
+
+func TestPotCap(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.PotCap = 700
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 200}  //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000} //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	require.NoError(t, table.StartGame())
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 600))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "allin", 0))
+	playHand(t, table)
+
+	// вклады 200, 600 и 600 в банк 1400: лишние 700 возвращаются в той же пропорции
+	require.Contains(t, recorder.events, fmt.Sprintf("Player %s get 100 refund over the pot cap", p1.GetId()))
+	require.Contains(t, recorder.events, fmt.Sprintf("Player %s get 300 refund over the pot cap", p2.GetId()))
+	require.Contains(t, recorder.events, fmt.Sprintf("Player %s get 300 refund over the pot cap", p3.GetId()))
+	paid := 0
+	for _, res := range table.Meta.ShowdownResults {
+		paid += res.Amount
+	}
+	require.Equal(t, 700, paid)
+	require.Equal(t, 2200, p1.GetBalance()+p2.GetBalance()+p3.GetBalance())
+}
+
+func TestAllInRunOut(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000} //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	require.NoError(t, table.StartGame())
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+	require.NoError(t, table.MakeMove(p1.GetId(), "allin", 0))
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+
+	// терн и ривер открыты без ходов
+	require.False(t, table.Meta.GameStarted)
+	require.Len(t, table.Meta.CommunityCards, 5)
+	dealt := 0
+	for _, e := range recorder.events {
+		if strings.HasPrefix(e, "Community cards") {
+			dealt++
+		}
+	}
+	require.Equal(t, 3, dealt)
+	require.Contains(t, recorder.events, "No more betting possible, dealing the next street")
+	require.Equal(t, 2000, p1.GetBalance()+p2.GetBalance())
+}
+
+func TestAllInCoverage(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 5000} //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 800}  //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	covered, uncalled := table.AllInCoverage(p2.GetId())
+	require.False(t, covered)
+	require.Equal(t, 0, uncalled)
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "allin", 0))
+	covered, uncalled = table.AllInCoverage(p1.GetId())
+	require.False(t, covered)
+	require.Equal(t, 4000, uncalled) // больше всех остальных стеков
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "allin", 0))
+	covered, uncalled = table.AllInCoverage(p2.GetId())
+	require.True(t, covered)
+	require.Equal(t, 0, uncalled)
+}
+
+func TestMoveAfterAllIn(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000} //bb
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000} //dealer
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000} //sb
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "allin", 0))
+	require.Equal(t, ErrPlayerAllIn, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.False(t, table.Meta.GameStarted) // все в олл-ине, раздача доиграна без ходов p2
+}
+
+func TestBankAmount(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, 1500, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 0}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 3000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 700}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	for _, p := range []*Player{p1, p2, p3} {
+		require.Equal(t, 1500, p.GetBalance()+p.GetLastBet())
+	}
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 300))
+	require.NoError(t, table.MakeMove(p3.GetId(), "fold", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "fold", 0))
+
+	// стек выдается только в начале турнира
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 4500, p1.GetBalance()+p2.GetBalance()+p3.GetBalance()+table.totalPot())
+	require.NotEqual(t, 1500, p2.GetBalance()+p2.GetLastBet())
+}
+
+func TestPotCommitment(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.Equal(t, 0.0, table.PotCommitment(p1.GetId()))
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 0.0, table.PotCommitment(p2.GetId()))
+	require.Equal(t, 0.1, table.PotCommitment(p1.GetId())) // большой блайнд
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 800))
+	require.Equal(t, 0.8, table.PotCommitment(p2.GetId()))
+	require.Equal(t, 0.0, table.PotCommitment("unknown"))
+}
+
+func TestMoveTimeout(t *testing.T) {
+	timer := &fakeTimer{}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.MoveTimeout = 30 * time.Second
+	table := NewPokerTable(config, meta)
+	table.SetTimer(timer)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 30*time.Second, timer.d)
+
+	// p2 не успел ответить на большой блайнд - фолд
+	timer.Fire()
+	require.True(t, p2.GetFold())
+	require.Equal(t, p3.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+
+	// ход вовремя отменяет таймер
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NotNil(t, timer.f)
+	require.Equal(t, p1.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+
+	// большой блайнд может чекнуть, таймаут делает чек
+	timer.Fire()
+	require.False(t, p1.GetFold())
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+
+	// устаревший таймер ничего не делает
+	stale := timer.f
+	require.NoError(t, table.MakeMove(table.Meta.PlayersOrder[table.Meta.PlayerTurnInd], "check", 0))
+	round, turn := table.Meta.CurrentRound, table.Meta.PlayerTurnInd
+	stale()
+	require.Equal(t, round, table.Meta.CurrentRound)
+	require.Equal(t, turn, table.Meta.PlayerTurnInd)
+}
+
+func TestGranularDealEvents(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.GranularDealEvents = true
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	dealt := []string{}
+	for _, e := range recorder.events {
+		require.False(t, strings.Contains(e, "get cards"))
+		if strings.HasPrefix(e, "DealtCard") {
+			dealt = append(dealt, e)
+		}
+	}
+	require.Len(t, dealt, 6) // по карте каждому игроку за два круга
+	require.Equal(t, "DealtCard: player "+p1.GetId()+" card 1", dealt[0])
+	require.Equal(t, "DealtCard: player "+p3.GetId()+" card 2", dealt[5])
+
+	for _, p := range []*Player{p1, p2, p3} {
+		require.Len(t, p.GetHand().Cards, 2)
+		for _, c := range p.GetHand().Cards { // в текст события карта не попадает, только в Cards
+			for _, e := range dealt {
+				require.NotContains(t, e, fmt.Sprint(c))
+			}
+		}
+	}
+}
+
+func TestGranularDealEventsPrivate(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.GranularDealEvents = true
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	own, opponent := &eventRecorder{}, &eventRecorder{}
+	table.AddPlayerObserver(p1.GetId(), own)
+	table.AddPlayerObserver(p2.GetId(), opponent)
+	require.NoError(t, table.StartGame())
+
+	dealtTo := func(r *eventRecorder, id string) []Card {
+		cards := []Card{}
+		for _, e := range r.raw {
+			if e.Kind == EventDealtCard {
+				require.Equal(t, id, e.PlayerID) // чужие карты наблюдателю не приходят
+				cards = append(cards, e.Cards...)
+			}
+		}
+		return cards
+	}
+	require.Equal(t, p1.GetHand().Cards, dealtTo(own, p1.GetId()))
+	require.Equal(t, p2.GetHand().Cards, dealtTo(opponent, p2.GetId()))
+}
+
+func TestTimeBank(t *testing.T) {
+	timer := &fakeTimer{}
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.MoveTimeout = 30 * time.Second
+	config.TimeBank = time.Minute
+	config.TimeBankRefill = 10 * time.Second
+	table := NewPokerTable(config, meta)
+	table.SetTimer(timer)
+	table.SetClock(clock)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+
+	// p2 думает дольше 30 секунд: вместо фолда включается банк
+	timer.Fire()
+	require.False(t, p2.GetFold())
+	require.Equal(t, time.Minute, timer.d)
+	require.Contains(t, recorder.events, fmt.Sprintf("Player %s uses time bank, 1m0s left", p2.GetId()))
+	clock.Advance(20 * time.Second)
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.Equal(t, 40*time.Second, table.Meta.TimeBanks[p2.GetId()])
+
+	require.NoError(t, table.MakeMove(p3.GetId(), "fold", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+
+	// флоп: p1 тратит весь банк и только потом чекает автоматически
+	timer.Fire()
+	clock.Advance(time.Minute)
+	timer.Fire()
+	require.Equal(t, time.Duration(0), table.Meta.TimeBanks[p1.GetId()])
+	require.Equal(t, p2.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+
+	// p2 еще раз залезает в банк
+	timer.Fire()
+	require.Equal(t, 40*time.Second, timer.d)
+	clock.Advance(15 * time.Second)
+	require.NoError(t, table.MakeMove(p2.GetId(), "bet", 100))
+	require.Equal(t, 25*time.Second, table.Meta.TimeBanks[p2.GetId()])
+
+	// без банка таймаут сразу фолдит
+	timer.Fire()
+	require.Contains(t, recorder.events, fmt.Sprintf("Player %s timed out, auto fold", p1.GetId()))
+	require.False(t, table.Meta.GameStarted)
+
+	// в новой раздаче банк пополняется, но не выше TimeBank
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 10*time.Second, table.Meta.TimeBanks[p1.GetId()])
+	require.Equal(t, 35*time.Second, table.Meta.TimeBanks[p2.GetId()])
+	require.Equal(t, time.Minute, table.Meta.TimeBanks[p3.GetId()])
+}
+
+func TestLastRake(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.Rake = 0.05
+	config.RakeCap = 30
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	// банк 300: 5% = 15, ниже потолка
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+	require.Equal(t, 15, table.LastRake())
+	require.Equal(t, 3000-15, p1.GetBalance()+p2.GetBalance()+p3.GetBalance())
+	require.Contains(t, recorder.events, "Hand ended, rake 15")
+
+	// банк 1200: 5% = 60, срезается до потолка
+	require.NoError(t, table.StartGame())
+	pId := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	require.NoError(t, table.MakeMove(pId, "raise", 400))
+	playHand(t, table)
+	require.Equal(t, 30, table.LastRake())
+	require.Equal(t, 3000-45, p1.GetBalance()+p2.GetBalance()+p3.GetBalance())
+	require.Contains(t, recorder.events, "Hand ended, rake 30")
+}
+
+func TestStructuredEvents(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 300))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+
+	require.Equal(t, EventStateSnapshot, recorder.raw[0].Kind)
+	require.Equal(t, Event{Kind: EventPlayerJoined, PlayerID: p1.GetId()}, recorder.raw[1])
+	require.Contains(t, recorder.raw, Event{Kind: EventRaise, PlayerID: p2.GetId(), Amount: 300})
+	require.Contains(t, recorder.raw, Event{Kind: EventCall, PlayerID: p1.GetId(), Amount: 300})
+
+	idx := slices.IndexFunc(recorder.raw, func(e Event) bool { return e.Kind == EventCommunityCards })
+	require.NotEqual(t, -1, idx)
+	require.Equal(t, int(Flop), recorder.raw[idx].Round)
+	require.Equal(t, table.Meta.CommunityCards, recorder.raw[idx].Cards)
+
+	// строки для текстовых клиентов остались прежними
+	require.Equal(t, fmt.Sprintf("Player %s do raise with 300 amount", p2.GetId()),
+		Event{Kind: EventRaise, PlayerID: p2.GetId(), Amount: 300}.String())
+	require.Equal(t, "Game started", Event{Kind: EventGameStarted}.String())
+}
+
+func TestPlayerObserver(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	all, own, other := &eventRecorder{}, &eventRecorder{}, &eventRecorder{}
+	table.AddObserver(all)
+	table.AddPlayerObserver(p1.GetId(), own)
+	table.AddPlayerObserver(p2.GetId(), other)
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+
+	holeCards := func(r *eventRecorder) []string {
+		ids := []string{}
+		for _, e := range r.raw {
+			if e.Kind == EventHoleCards {
+				ids = append(ids, e.PlayerID)
+			}
+		}
+		return ids
+	}
+	require.ElementsMatch(t, []string{p1.GetId(), p2.GetId()}, holeCards(all))
+	require.Equal(t, []string{p1.GetId()}, holeCards(own))
+	require.Equal(t, []string{p2.GetId()}, holeCards(other))
+	for _, e := range other.events {
+		require.NotContains(t, e, fmt.Sprint(p1.GetHand().Cards))
+	}
+
+	// публичные события получают все
+	require.Contains(t, other.raw, Event{Kind: EventGameStarted})
+	require.Contains(t, own.raw, Event{Kind: EventGameStarted})
+}
+
+func TestEventPrivate(t *testing.T) {
+	cards := []Card{{Suit: "Spades", Value: 14}}
+	tests := []struct {
+		name  string
+		event Event
+		want  bool
+	}{
+		{"Hole cards", Event{Kind: EventHoleCards, PlayerID: "p1", Cards: cards}, true},
+		{"Dealt card", Event{Kind: EventDealtCard, PlayerID: "p1", Cards: cards}, true},
+		{"Any kind with cards of a player", Event{Kind: EventStraddle, PlayerID: "p1", Cards: cards}, true},
+		{"Showdown reveal", Event{Kind: EventReveal, PlayerID: "p1", Cards: cards}, false},
+		{"Community cards", Event{Kind: EventCommunityCards, Cards: cards}, false},
+		{"Move", Event{Kind: EventBet, PlayerID: "p1", Amount: 20}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.event.Private())
+		})
+	}
+}
+
+func TestChipLeaderAndShortStack(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	id, amount := table.ChipLeader()
+	require.Equal(t, "", id)
+	require.Equal(t, 0, amount)
+
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 5000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 300}
+	p4 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000004"), Balance: 5000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.AddPlayer(p4))
+
+	id, amount = table.ChipLeader()
+	require.Equal(t, p2.GetId(), id) // при равенстве - первый по рассадке
+	require.Equal(t, 5000, amount)
+	id, amount = table.ShortStack()
+	require.Equal(t, p3.GetId(), id)
+	require.Equal(t, 300, amount)
+}
+
+func TestRebuy(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, 1500, false)
+	config.RebuyPeriod = time.Hour
+	table := NewPokerTable(config, meta)
+	table.SetClock(clock)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 0}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 0}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.Equal(t, ErrPlayerNotFound, table.Rebuy("unknown", 1500))
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, ErrGameStarted, table.Rebuy(p1.GetId(), 1500)) // посреди раздачи нельзя
+	require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+
+	clock.Advance(40 * time.Minute)
+	require.Equal(t, ErrInvalidRebuy, table.Rebuy(p2.GetId(), 0))
+	require.NoError(t, table.Rebuy(p2.GetId(), 1500))
+	require.Equal(t, 2950, p2.GetBalance())
+
+	clock.Advance(30 * time.Minute)
+	require.Equal(t, ErrRebuyClosed, table.Rebuy(p2.GetId(), 1500))
+	require.Equal(t, 2950, p2.GetBalance())
+}
+
+func TestRebuyBustedPlayer(t *testing.T) {
+	meta := NewTableMeta(50, 0, 7)
+	config := NewTableConfig(time.Hour, 10, 2, 1000, false)
+	config.RebuyPeriod = time.Hour
+	config.MaxRebuys = 1
+	config.AllowAddOn = true
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001")}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002")}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	// p2 идет олл-ин, p1 отвечает: кто-то из них остается без фишек
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "allin", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.False(t, table.Meta.GameStarted)
+	busted := p1
+	if p2.GetBalance() == 0 {
+		busted = p2
+	}
+	require.Equal(t, 0, busted.GetBalance())
+
+	require.NoError(t, table.Rebuy(busted.GetId(), 1000))
+	require.Equal(t, 1000, busted.GetBalance())
+	require.Equal(t, ErrRebuyLimit, table.Rebuy(busted.GetId(), 1000))
+	require.NoError(t, table.AddOn(busted.GetId(), 500))
+	require.Equal(t, ErrAddOnTaken, table.AddOn(busted.GetId(), 500))
+	require.Equal(t, 1500, busted.GetBalance())
+	require.Contains(t, recorder.raw, Event{Kind: EventAddOn, PlayerID: busted.GetId(), Amount: 500})
+
+	// докупившийся игрок снова играет
+	require.NoError(t, table.StartGame())
+	require.Contains(t, table.Meta.PlayersOrder, busted.GetId())
+	require.Len(t, busted.GetHand().Cards, 2)
+}
+
+func TestHiLo(t *testing.T) {
+	board := []Card{
+		{Suit: "Hearts", Value: 2}, {Suit: "Diamonds", Value: 5}, {Suit: "Clubs", Value: 7},
+		{Suit: "Clubs", Value: 13}, {Suit: "Spades", Value: 12},
+	}
+	testTable := []struct {
+		Name      string
+		Community []Card
+		Hands     []Hand
+		Expected  []int
+	}{
+		{
+			Name:      "Scoop",
+			Community: board,
+			Hands: []Hand{
+				{[]Card{{Suit: "Hearts", Value: 14}, {Suit: "Diamonds", Value: 3}}},
+				{[]Card{{Suit: "Spades", Value: 9}, {Suit: "Spades", Value: 10}}},
+			},
+			Expected: []int{1001, 0},
+		},
+		{
+			Name: "No qualifying low",
+			Community: []Card{
+				{Suit: "Hearts", Value: 13}, {Suit: "Diamonds", Value: 13}, {Suit: "Clubs", Value: 7},
+				{Suit: "Spades", Value: 9}, {Suit: "Diamonds", Value: 2},
+			},
+			Hands: []Hand{
+				{[]Card{{Suit: "Hearts", Value: 14}, {Suit: "Diamonds", Value: 3}}},
+				{[]Card{{Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 12}}},
+			},
+			Expected: []int{0, 1001},
+		},
+		{
+			Name:      "Quartered low",
+			Community: board,
+			Hands: []Hand{
+				{[]Card{{Suit: "Hearts", Value: 14}, {Suit: "Diamonds", Value: 3}}},
+				{[]Card{{Suit: "Spades", Value: 14}, {Suit: "Clubs", Value: 3}}},
+				{[]Card{{Suit: "Spades", Value: 13}, {Suit: "Diamonds", Value: 13}}},
+			},
+			Expected: []int{250, 250, 501},
+		},
+	}
+	for _, tCase := range testTable {
+		t.Run(tCase.Name, func(t *testing.T) {
+			meta := NewTableMeta(50, 0, 1488)
+			config := NewTableConfig(time.Hour, 10, 2, -1, false)
+			config.HiLo = true
+			table := NewPokerTable(config, meta)
+			players := []*Player{}
+			meta.Players = map[string]IPlayer{}
+			for i, hand := range tCase.Hands {
+				p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i+1)), Hand: hand}
+				players = append(players, p)
+				meta.Players[p.GetId()] = p
+				meta.PlayersOrder = append(meta.PlayersOrder, p.GetId())
+			}
+			meta.CommunityCards = tCase.Community
+			meta.Pots = []Pot{{Amount: 1001, Applicants: slices.Clone(meta.PlayersOrder)}}
+
+			table.PayMoney()
+			for i, p := range players {
+				require.Equal(t, tCase.Expected[i], p.GetBalance())
+			}
+		})
+	}
+}
+
+func TestOmahaDeal(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.Omaha = true
+	config.HoleCards = 1
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.Equal(t, ErrOmahaHoleCards, table.StartGame())
+
+	table.Config.HoleCards = 4
+	require.NoError(t, table.StartGame())
+	require.Len(t, p1.GetHand().Cards, 4)
+	require.Len(t, p2.GetHand().Cards, 4)
+	playHand(t, table)
+	require.Equal(t, 2000, p1.GetBalance()+p2.GetBalance())
+}
+
+func TestShortDeckTable(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.ShortDeck = true
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+
+	dealt := slices.Concat(table.Meta.Deck, p1.GetHand().Cards, p2.GetHand().Cards)
+	require.ElementsMatch(t, GetShortDeck(), dealt)
+	playHand(t, table)
+	require.Equal(t, 2000, p1.GetBalance()+p2.GetBalance())
+}
+
+func TestUncalledBet(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 140}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+
+	// флоп: p3 ставит 100, p1 коллирует олл-ин на 40, p2 сбрасывает
+	require.NoError(t, table.MakeMove(p3.GetId(), "bet", 100))
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+	require.False(t, table.Meta.GameStarted)
+
+	require.Contains(t, recorder.raw, Event{Kind: EventUncalledBet, PlayerID: p3.GetId(), Amount: 60})
+	total := 0
+	for _, res := range table.Meta.ShowdownResults {
+		total += res.Amount
+	}
+	require.Equal(t, 300+80, total) // 60 не участвуют во вскрытии
+	require.Equal(t, 2140, p1.GetBalance()+p2.GetBalance()+p3.GetBalance())
+}
+
+func TestSidePotsEligibility(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	hands := []Hand{
+		{[]Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}},
+		{[]Card{{Suit: "Spades", Value: 13}, {Suit: "Hearts", Value: 13}}},
+		{[]Card{{Suit: "Spades", Value: 12}, {Suit: "Hearts", Value: 12}}},
+		{[]Card{{Suit: "Hearts", Value: 3}, {Suit: "Spades", Value: 5}}},
+	}
+	bets := []int{50, 100, 200, 200} // три олл-ина разного размера и колл
+	players := []*Player{}
+	meta.Players = map[string]IPlayer{}
+	for i := range hands {
+		p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i+1)), Hand: hands[i], LastBet: bets[i], AllIn: i < 3}
+		players = append(players, p)
+		meta.Players[p.GetId()] = p
+		meta.PlayersOrder = append(meta.PlayersOrder, p.GetId())
+	}
+	meta.CommunityCards = []Card{
+		{Suit: "Clubs", Value: 2}, {Suit: "Diamonds", Value: 7}, {Suit: "Hearts", Value: 9},
+		{Suit: "Spades", Value: 11}, {Suit: "Clubs", Value: 4},
+	}
+	meta.Pots = CreatePots(meta.Players)
+	require.Equal(t, []Pot{
+		{Amount: 200, Applicants: slices.Clone(meta.PlayersOrder)},
+		{Amount: 150, Applicants: slices.Clone(meta.PlayersOrder[1:])},
+		{Amount: 200, Applicants: slices.Clone(meta.PlayersOrder[2:])},
+	}, meta.Pots)
+
+	// каждый банк достается лучшей руке среди тех, кто в него вложился
+	table.PayMoney()
+	require.Equal(t, []int{200, 150, 200, 0}, []int{
+		players[0].GetBalance(), players[1].GetBalance(), players[2].GetBalance(), players[3].GetBalance(),
+	})
+}
+
+func TestOddChipAfterRemoval(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	players := []*Player{}
+	for i, suit := range []string{"Clubs", "Diamonds", "Hearts", "Hearts"} {
+		p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i+1)),
+			Hand: Hand{[]Card{{Suit: suit, Value: 2 + i}, {Suit: suit, Value: 7 + i}}}}
+		players = append(players, p)
+		require.NoError(t, table.AddPlayer(p))
+	}
+	require.NoError(t, table.RemovePlayer(players[3].GetId()))
+
+	// на борде роял-флеш, банк из 1001 делится на троих
+	meta.CommunityCards = []Card{
+		{Suit: "Spades", Value: 14}, {Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 12},
+		{Suit: "Spades", Value: 11}, {Suit: "Spades", Value: 10},
+	}
+	meta.DealerIndex = 1
+	meta.Pots = []Pot{{Amount: 1001, Applicants: slices.Clone(meta.PlayersOrder)}}
+	table.PayMoney()
+	// две лишние фишки получают первые победители слева от баттона: p3, затем p1
+	require.Equal(t, []int{334, 333, 334}, []int{players[0].GetBalance(), players[1].GetBalance(), players[2].GetBalance()})
+}
+
+func TestAnteOnlyOncePreflop(t *testing.T) {
+	meta := NewTableMeta(50, 10, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+
+	// анте уже собрано, повторный вызов ничего не списывает
+	balances := []int{p1.GetBalance(), p2.GetBalance()}
+	require.Equal(t, ErrAnteNotAllowed, table.betAnte())
+	require.Equal(t, balances, []int{p1.GetBalance(), p2.GetBalance()})
+
+	require.NoError(t, table.MakeMove(table.Meta.PlayersOrder[table.Meta.PlayerTurnInd], "call", 0))
+	require.NoError(t, table.MakeMove(table.Meta.PlayersOrder[table.Meta.PlayerTurnInd], "check", 0))
+	require.Equal(t, int(Flop), table.Meta.CurrentRound)
+	balances = []int{p1.GetBalance(), p2.GetBalance()}
+	require.Equal(t, ErrAnteNotAllowed, table.betAnte())
+	require.Equal(t, balances, []int{p1.GetBalance(), p2.GetBalance()})
+
+	// в следующей раздаче анте снова собирается
+	playHand(t, table)
+	require.NoError(t, table.StartGame())
+	require.Equal(t, []Pot{{Amount: 20, Applicants: []string{p1.GetId(), p2.GetId()}}}, table.Meta.Pots)
+}
+
+func TestBigBlindAnte(t *testing.T) {
+	newTable := func(bbBalance int) (*PokerTable, *Player, *Player, *Player, *eventRecorder) {
+		meta := NewTableMeta(50, 30, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		config.AnteType = BigBlindAnte
+		table := NewPokerTable(config, meta)
+		recorder := &eventRecorder{}
+		table.AddObserver(recorder)
+		p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: bbBalance} //bb
+		p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}      //dealer
+		p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}      //sb
+		require.NoError(t, table.AddPlayer(p1))
+		require.NoError(t, table.AddPlayer(p2))
+		require.NoError(t, table.AddPlayer(p3))
+		require.NoError(t, table.StartGame())
+		return table, p1, p2, p3, recorder
+	}
+
+	t.Run("only the big blind pays", func(t *testing.T) {
+		table, p1, p2, p3, recorder := newTable(1000)
+		require.Equal(t, []int{870, 1000, 950}, []int{p1.GetBalance(), p2.GetBalance(), p3.GetBalance()})
+		require.Len(t, table.Meta.Pots, 1)
+		require.Equal(t, 30, table.Meta.Pots[0].Amount)
+		require.ElementsMatch(t, table.Meta.PlayersOrder, table.Meta.Pots[0].Applicants)
+		require.Contains(t, recorder.raw, Event{Kind: EventAnte, PlayerID: p1.GetId(), Amount: 30})
+
+		playHand(t, table)
+		require.Equal(t, 3000, p1.GetBalance()+p2.GetBalance()+p3.GetBalance())
+	})
+
+	t.Run("short big blind", func(t *testing.T) {
+		table, p1, _, _, recorder := newTable(20)
+		require.Equal(t, 0, p1.GetBalance())
+		require.True(t, p1.IsAllIn())
+		require.Equal(t, 20, table.Meta.Pots[0].Amount)
+		require.Contains(t, recorder.raw, Event{Kind: EventPartialAnte, PlayerID: p1.GetId(), Amount: 20})
+	})
+}
+
+func TestRemovePlayerDuringHand(t *testing.T) {
+	// p1 первым ходит на префлопе, p2 дилер, p3 малый и p4 большой блайнд
+	newTable := func() (*PokerTable, []*Player) {
+		meta := NewTableMeta(50, 0, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		table := NewPokerTable(config, meta)
+		players := []*Player{}
+		for i := 1; i <= 4; i++ {
+			p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000}
+			players = append(players, p)
+			require.NoError(t, table.AddPlayer(p))
+		}
+		require.NoError(t, table.StartGame())
+		require.Equal(t, players[1].GetId(), table.Meta.PlayersOrder[table.Meta.DealerIndex])
+		require.Equal(t, players[0].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+		return table, players
+	}
+	turn := func(table *PokerTable) string {
+		return table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	}
+
+	t.Run("current actor", func(t *testing.T) {
+		table, players := newTable()
+		require.NoError(t, table.RemovePlayer(players[0].GetId()))
+		require.Equal(t, players[1].GetId(), turn(table))
+		require.Equal(t, players[1].GetId(), table.Meta.PlayersOrder[table.Meta.DealerIndex])
+	})
+
+	t.Run("dealer", func(t *testing.T) {
+		table, players := newTable()
+		require.NoError(t, table.RemovePlayer(players[1].GetId()))
+		require.Equal(t, players[0].GetId(), turn(table))
+		require.NoError(t, table.MakeMove(players[0].GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(players[2].GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(players[3].GetId(), "check", 0))
+		// баттон остался за местом дилера, флоп начинает малый блайнд
+		require.Equal(t, int(Flop), table.Meta.CurrentRound)
+		require.Equal(t, players[2].GetId(), turn(table))
+	})
+
+	t.Run("player before the actor", func(t *testing.T) {
+		table, players := newTable()
+		require.NoError(t, table.MakeMove(players[0].GetId(), "call", 0))
+		require.Equal(t, players[1].GetId(), turn(table))
+		require.NoError(t, table.RemovePlayer(players[0].GetId()))
+		require.Equal(t, players[1].GetId(), turn(table))
+		require.Equal(t, players[1].GetId(), table.Meta.PlayersOrder[table.Meta.DealerIndex])
+
+		// колл ушедшего игрока остается в банке
+		playHand(t, table)
+		require.Equal(t, 900, players[0].GetBalance())
+		require.Equal(t, 3100, players[1].GetBalance()+players[2].GetBalance()+players[3].GetBalance())
+	})
+}
+
+func TestMaxPlayers(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 6, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	for i := 1; i <= 6; i++ {
+		p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000}
+		require.NoError(t, table.AddPlayer(p))
+	}
+	require.Len(t, table.Meta.PlayersOrder, 6)
+	p7 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000007"), Balance: 1000}
+	require.Equal(t, ErrMaxPlayers, table.AddPlayer(p7))
+}
+
+func TestDuplicatePlayer(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, true)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	twin := &Player{Id: p1.Id, Balance: 5}
+	require.Equal(t, ErrDuplicatePlayer, table.AddPlayer(twin))
+	require.Same(t, p1, table.Meta.Players[p1.GetId()])
+	require.Equal(t, []string{p1.GetId(), p2.GetId()}, table.Meta.PlayersOrder)
+
+	// в очереди на вход тоже нельзя занять чужой id
+	require.NoError(t, table.StartGame())
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p3))
+	require.Equal(t, ErrDuplicatePlayer, table.AddPlayer(&Player{Id: p3.Id}))
+	require.Equal(t, ErrDuplicatePlayer, table.AddPlayer(twin))
+	require.Same(t, p3, table.Meta.Query[p3.GetId()])
+}
+
+func TestSitOut(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	players := []*Player{}
+	for i := 1; i <= 4; i++ {
+		p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000}
+		players = append(players, p)
+		require.NoError(t, table.AddPlayer(p))
+	}
+	p1, p2, p3, p4 := players[0], players[1], players[2], players[3]
+	require.Equal(t, ErrPlayerNotFound, table.SitOut("unknown"))
+	require.NoError(t, table.SitOut(p4.GetId()))
+
+	// p4 не получает карт и не ставит блайнд: p2 дилер, p3 малый, p1 большой блайнд
+	require.NoError(t, table.StartGame())
+	require.Empty(t, p4.GetHand().Cards)
+	require.Len(t, p1.GetHand().Cards, 2)
+	require.Equal(t, []int{900, 1000, 950, 1000}, []int{p1.GetBalance(), p2.GetBalance(), p3.GetBalance(), p4.GetBalance()})
+	require.Equal(t, p2.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+	for table.Meta.GameStarted {
+		pId := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+		require.NotEqual(t, p4.GetId(), pId)
+		require.NoError(t, table.MakeMove(pId, "call", 0))
+	}
+	require.Equal(t, 1000, p4.GetBalance())
+
+	// вернувшийся игрок получает карты в следующей раздаче
+	require.NoError(t, table.SitIn(p4.GetId()))
+	require.Equal(t, ErrPlayerNotFound, table.SitIn(p4.GetId()))
+	require.NoError(t, table.StartGame())
+	require.Len(t, p4.GetHand().Cards, 2)
+
+	// ушедший с картами игрок сразу сбрасывает их
+	actor := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	other := table.Meta.PlayersOrder[(table.Meta.PlayerTurnInd+1)%4]
+	require.NoError(t, table.SitOut(other))
+	require.True(t, table.Meta.Players[other].GetFold())
+	require.Contains(t, recorder.raw, Event{Kind: EventFold, PlayerID: other})
+	require.Equal(t, actor, table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+
+	require.NoError(t, table.SitOut(p1.GetId()))
+	require.NoError(t, table.SitOut(p2.GetId()))
+	require.NoError(t, table.SitOut(p3.GetId()))
+	playHand(t, table)
+	require.Equal(t, ErrNotEnoughPlayers, table.StartGame())
+}
+
+func TestBuyInBounds(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.MinBuyIn = 500
+	config.MaxBuyIn = 2000
+	table := NewPokerTable(config, meta)
+	small := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 499}
+	big := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 2001}
+	require.Equal(t, ErrBuyInTooSmall, table.AddPlayer(small))
+	require.Equal(t, ErrBuyInTooBig, table.AddPlayer(big))
+	require.Empty(t, table.Meta.PlayersOrder)
+
+	small.Balance, big.Balance = 500, 2000
+	require.NoError(t, table.AddPlayer(small))
+	require.NoError(t, table.AddPlayer(big))
+	require.Len(t, table.Meta.PlayersOrder, 2)
+}
+
+func TestRebuyBuyInBounds(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, 1000, false)
+	config.RebuyPeriod = time.Hour
+	config.MinBuyIn = 500
+	config.MaxBuyIn = 1500
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001")}
+	require.NoError(t, table.AddPlayer(p1))
+
+	require.Equal(t, ErrBuyInTooSmall, table.Rebuy(p1.GetId(), 100))
+	p1.Balance = 1000
+	require.NoError(t, table.Rebuy(p1.GetId(), 1000)) // срезается до максимального стека
+	require.Equal(t, 1500, p1.GetBalance())
+	require.Equal(t, ErrBuyInTooBig, table.Rebuy(p1.GetId(), 500))
+}
+
+// stackedShuffler кладет заданные карты наверх колоды, по одному набору на раздачу
+type stackedShuffler struct {
+	tops [][]Card
+}
+
+func (s *stackedShuffler) Shuffle(deck []Card) ([]Card, []byte, error) {
+	top := s.tops[0]
+	s.tops = s.tops[1:]
+	rest := slices.DeleteFunc(deck, func(c Card) bool { return slices.Contains(top, c) })
+	return slices.Concat(top, rest), nil, nil
+}
+
+func TestEliminateBusted(t *testing.T) {
+	aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+	junk := []Card{{Suit: "Clubs", Value: 7}, {Suit: "Diamonds", Value: 8}}
+	junk2 := []Card{{Suit: "Clubs", Value: 9}, {Suit: "Diamonds", Value: 10}}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, 1000, false)
+	// первая раздача: p1, p2, p3; вторая в хендз апе: p2, p3
+	config.Shuffler = &stackedShuffler{tops: [][]Card{slices.Concat(junk, junk2, aces), slices.Concat(junk2, aces)}}
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001")}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002")}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003")}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "allin", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.Equal(t, []string{p1.GetId()}, table.Meta.Eliminated)
+	require.Equal(t, []string{p2.GetId(), p3.GetId()}, table.Meta.PlayersOrder)
+	require.Contains(t, recorder.raw, Event{Kind: EventEliminated, PlayerID: p1.GetId(), Index: 3})
+
+	require.NoError(t, table.StartGame())
+	pId := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	require.NoError(t, table.MakeMove(pId, "allin", 0))
+	pId = table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+	require.NoError(t, table.MakeMove(pId, "call", 0))
+
+	// выбывшие записаны в порядке вылета, то есть от последнего места к первому
+	require.Equal(t, []string{p1.GetId(), p2.GetId()}, table.Meta.Eliminated)
+	require.Contains(t, recorder.raw, Event{Kind: EventEliminated, PlayerID: p2.GetId(), Index: 2})
+	require.Contains(t, recorder.raw, Event{Kind: EventGameOver, PlayerID: p3.GetId()})
+	require.Equal(t, 3000, p3.GetBalance())
+}
+
+func TestGameOver(t *testing.T) {
+	for _, bank := range []int{1000, -1} {
+		meta := NewTableMeta(50, 0, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, bank, false)
+		table := NewPokerTable(config, meta)
+		recorder := &eventRecorder{}
+		table.AddObserver(recorder)
+		p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+		p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+		require.NoError(t, table.AddPlayer(p1))
+		require.NoError(t, table.AddPlayer(p2))
+
+		// играем олл-ин каждую раздачу, пока у кого-то не кончатся фишки
+		for hands := 0; !table.IsGameOver(); hands++ {
+			require.Less(t, hands, 100)
+			require.Empty(t, table.Winner())
+			require.NoError(t, table.StartGame())
+			for table.Meta.GameStarted {
+				pId := table.Meta.PlayersOrder[table.Meta.PlayerTurnInd]
+				if table.Meta.CurrentBet > table.Meta.Players[pId].GetLastBet() {
+					require.NoError(t, table.MakeMove(pId, "call", 0))
+				} else {
+					require.NoError(t, table.MakeMove(pId, "allin", 0))
+				}
+			}
+		}
+
+		winner := table.Winner()
+		require.Contains(t, []string{p1.GetId(), p2.GetId()}, winner)
+		require.Equal(t, 2000, p1.GetBalance()+p2.GetBalance())
+		require.Equal(t, Event{Kind: EventGameOver, PlayerID: winner}, recorder.raw[len(recorder.raw)-1])
+		require.ErrorIs(t, table.StartGame(), ErrGameOver)
+		require.ErrorIs(t, table.NewRound(), ErrGameOver)
+	}
+}
+
+func TestRoundNames(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.Equal(t, "predeal", RoundName(table.Meta.CurrentRound))
+
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+	require.Equal(t, RoundPreDeal, table.Meta.CurrentRound)
+
+	phases := []string{}
+	for _, e := range recorder.raw {
+		if e.Kind == EventNewRound {
+			phases = append(phases, RoundName(e.Round))
+		}
+	}
+	require.Equal(t, []string{"preflop", "flop", "turn", "river", "showdown"}, phases)
+	require.Contains(t, recorder.events, "New round started. Current round: 1 (flop)")
+	require.Equal(t, "round 7", RoundName(7))
+}
+
+func TestHeadsUpActionOrder(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	// две раздачи, чтобы кнопка побывала у обоих игроков
+	for range 2 {
+		require.NoError(t, table.StartGame())
+		button := table.Meta.PlayersOrder[table.Meta.DealerIndex]
+		bigBlind := table.Meta.PlayersOrder[(table.Meta.DealerIndex+1)%2]
+		require.Equal(t, button, table.Meta.PlayersOrder[table.Meta.PlayerTurnInd], "preflop")
+		require.NoError(t, table.MakeMove(button, "call", 0))
+		require.NoError(t, table.MakeMove(bigBlind, "check", 0))
+
+		for _, round := range []int{RoundFlop, RoundTurn, RoundRiver} {
+			require.Equal(t, round, table.Meta.CurrentRound)
+			require.Equal(t, bigBlind, table.Meta.PlayersOrder[table.Meta.PlayerTurnInd], RoundName(round))
+			require.NoError(t, table.MakeMove(bigBlind, "check", 0))
+			require.NoError(t, table.MakeMove(button, "check", 0))
+		}
+		require.False(t, table.Meta.GameStarted)
+	}
+}
+
+func TestDeadButton(t *testing.T) {
+	newTable := func() (*PokerTable, *eventRecorder, []*Player) {
+		meta := NewTableMeta(50, 0, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		table := NewPokerTable(config, meta)
+		recorder := &eventRecorder{}
+		table.AddObserver(recorder)
+		players := []*Player{}
+		for i := 1; i <= 4; i++ {
+			p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000}
+			require.NoError(t, table.AddPlayer(p))
+			players = append(players, p)
+		}
+		// первая раздача: дилер p2, малый блайнд p3, большой p4
+		require.NoError(t, table.StartGame())
+		require.Equal(t, BlindPositions{SmallBlindIndex: 2, BigBlindIndex: 3,
+			SmallBlind: players[2].GetId(), BigBlind: players[3].GetId()}, table.Meta.Blinds)
+		playHand(t, table)
+		recorder.raw = nil
+		return table, recorder, players
+	}
+
+	t.Run("dead small blind", func(t *testing.T) {
+		table, recorder, p := newTable()
+		// p4 должен был ставить малый блайнд, большой все равно переходит к p1
+		require.NoError(t, table.RemovePlayer(p[3].GetId()))
+		require.NoError(t, table.StartGame())
+		require.Contains(t, recorder.raw, Event{Kind: EventDealer, PlayerID: p[2].GetId()})
+		require.False(t, slices.ContainsFunc(recorder.raw, func(e Event) bool { return e.Kind == EventSmallBlind }))
+		require.Contains(t, recorder.raw, Event{Kind: EventBigBlind, PlayerID: p[0].GetId(), Amount: 100})
+		require.Equal(t, p[1].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+		require.NoError(t, table.MakeMove(p[1].GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p[2].GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p[0].GetId(), "check", 0))
+		// после флопа первым ходит большой блайнд, баттон последним
+		require.Equal(t, p[0].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+	})
+
+	t.Run("dead button", func(t *testing.T) {
+		table, recorder, p := newTable()
+		// баттон должен был перейти к ушедшему p3 и остается на его пустом месте
+		require.NoError(t, table.RemovePlayer(p[2].GetId()))
+		require.NoError(t, table.StartGame())
+		require.Contains(t, recorder.raw, Event{Kind: EventDealer})
+		require.True(t, table.Meta.Blinds.DeadButton)
+		require.Contains(t, recorder.raw, Event{Kind: EventSmallBlind, PlayerID: p[3].GetId(), Amount: 50})
+		require.Contains(t, recorder.raw, Event{Kind: EventBigBlind, PlayerID: p[0].GetId(), Amount: 100})
+		require.Equal(t, p[1].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+		require.NoError(t, table.MakeMove(p[1].GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p[3].GetId(), "call", 0))
+		require.NoError(t, table.MakeMove(p[0].GetId(), "check", 0))
+		require.Equal(t, p[3].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+		playHand(t, table)
+
+		// следующая раздача снова с живым баттоном у прошлого малого блайнда
+		require.NoError(t, table.StartGame())
+		require.Equal(t, p[3].GetId(), table.Meta.PlayersOrder[table.Meta.DealerIndex])
+		require.False(t, table.Meta.Blinds.DeadButton)
+		require.Equal(t, p[0].GetId(), table.Meta.Blinds.SmallBlind)
+		require.Equal(t, p[1].GetId(), table.Meta.Blinds.BigBlind)
+	})
+}
+
+func TestPauseResume(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	timer := &fakeTimer{}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(10*time.Minute, 10, 2, -1, false)
+	config.LastBlindIncrease = clock.Now()
+	config.MoveTimeout = 30 * time.Second
+	table := NewPokerTable(config, meta)
+	table.SetClock(clock)
+	table.SetTimer(timer)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.ErrorIs(t, table.Resume(), ErrGameNotPaused)
+	require.NoError(t, table.StartGame())
+	clock.Advance(5 * time.Minute)
+	require.NoError(t, table.Pause())
+	require.ErrorIs(t, table.Pause(), ErrGamePaused)
+	require.Nil(t, timer.f, "move timer is suspended")
+	require.ErrorIs(t, table.MakeMove(p2.GetId(), "call", 0), ErrGamePaused)
+	require.ErrorIs(t, table.NewRound(), ErrGamePaused)
+
+	clock.Advance(time.Hour)
+	require.NoError(t, table.Resume())
+	require.Contains(t, recorder.events, "Game resumed after 1h0m0s")
+	require.NotNil(t, timer.f)
+	require.Equal(t, 30*time.Second, timer.d)
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	playHand(t, table)
+
+	// пауза не идет в зачет уровня: прошло 5 минут игры из 10
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 50, table.Meta.SmallBlind)
+	playHand(t, table)
+
+	require.NoError(t, table.Pause())
+	require.ErrorIs(t, table.StartGame(), ErrGamePaused)
+	require.NoError(t, table.Resume())
+	clock.Advance(5 * time.Minute)
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 100, table.Meta.SmallBlind)
+}
+
+func TestRevealOrder(t *testing.T) {
+	queens := []Card{{Suit: "Spades", Value: 12}, {Suit: "Hearts", Value: 12}}
+	aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+	kings := []Card{{Suit: "Spades", Value: 13}, {Suit: "Hearts", Value: 13}}
+	// на борде каре двоек, решает старшая карманная карта: у p2 тузы, у p3 короли, у p1 дамы
+	tests := []struct {
+		name     string
+		riverBet bool
+		muck     bool
+		revealed []int
+		mucked   []int
+	}{
+		{name: "last aggressor first", riverBet: true, revealed: []int{2, 3, 1}},
+		{name: "left of the button without bets", revealed: []int{3, 1, 2}},
+		{name: "losers muck", riverBet: true, muck: true, revealed: []int{2}, mucked: []int{3, 1}},
+		{name: "winner always shows", muck: true, revealed: []int{3, 2}, mucked: []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := NewTableMeta(50, 0, 1488)
+			config := NewTableConfig(time.Hour, 10, 2, -1, false)
+			config.Shuffler = &stackedShuffler{tops: [][]Card{slices.Concat(queens, aces, kings)}}
+			config.MuckLosers = tt.muck
+			table := NewPokerTable(config, meta)
+			recorder := &eventRecorder{}
+			table.AddObserver(recorder)
+			p := []*Player{}
+			for i := 1; i <= 3; i++ {
+				p = append(p, &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000})
+				require.NoError(t, table.AddPlayer(p[i-1]))
+			}
+			require.NoError(t, table.StartGame())
+			require.NoError(t, table.MakeMove(p[1].GetId(), "call", 0))
+			require.NoError(t, table.MakeMove(p[2].GetId(), "call", 0))
+			require.NoError(t, table.MakeMove(p[0].GetId(), "check", 0))
+			for table.Meta.CurrentRound < RoundRiver {
+				require.NoError(t, table.MakeMove(p[2].GetId(), "check", 0))
+				require.NoError(t, table.MakeMove(p[0].GetId(), "check", 0))
+				require.NoError(t, table.MakeMove(p[1].GetId(), "check", 0))
+			}
+			require.NoError(t, table.MakeMove(p[2].GetId(), "check", 0))
+			require.NoError(t, table.MakeMove(p[0].GetId(), "check", 0))
+			if tt.riverBet {
+				require.NoError(t, table.MakeMove(p[1].GetId(), "bet", 100))
+				require.NoError(t, table.MakeMove(p[2].GetId(), "call", 0))
+				require.NoError(t, table.MakeMove(p[0].GetId(), "call", 0))
+			} else {
+				require.NoError(t, table.MakeMove(p[1].GetId(), "check", 0))
+			}
+			require.False(t, table.Meta.GameStarted)
+
+			revealed, mucked := []string{}, []string{}
+			for _, e := range recorder.raw {
+				switch e.Kind {
+				case EventReveal:
+					revealed = append(revealed, e.PlayerID)
+					require.Len(t, e.Cards, 2)
+					require.Equal(t, FourOfAKind, e.Rank)
+				case EventMuck:
+					mucked = append(mucked, e.PlayerID)
+				case EventWinner:
+					require.Len(t, revealed, len(tt.revealed), "hands are revealed before the payout")
+				}
+			}
+			expected := func(seats []int) []string {
+				ids := []string{}
+				for _, s := range seats {
+					ids = append(ids, p[s-1].GetId())
+				}
+				return ids
+			}
+			require.Equal(t, expected(tt.revealed), revealed)
+			require.Equal(t, expected(tt.mucked), mucked)
+		})
+	}
+}
+
+func TestRunItTwice(t *testing.T) {
+	aces := []Card{{Suit: "Spades", Value: 14}, {Suit: "Hearts", Value: 14}}
+	kings := []Card{{Suit: "Spades", Value: 13}, {Suit: "Hearts", Value: 13}}
+	// на первом борде у p2 сет королей, на втором тузы p1 держатся
+	first := []Card{{Suit: "Diamonds", Value: 13}, {Suit: "Clubs", Value: 2}, {Suit: "Hearts", Value: 7}, {Suit: "Spades", Value: 9}, {Suit: "Diamonds", Value: 3}}
+	second := []Card{{Suit: "Clubs", Value: 4}, {Suit: "Diamonds", Value: 5}, {Suit: "Hearts", Value: 8}, {Suit: "Clubs", Value: 11}, {Suit: "Diamonds", Value: 2}}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.RunItTwice = true
+	config.Shuffler = &stackedShuffler{tops: [][]Card{slices.Concat(aces, kings, first, second)}}
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "allin", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.False(t, table.Meta.GameStarted)
+
+	require.Equal(t, first, table.Meta.CommunityCards)
+	require.Equal(t, second, table.Meta.SecondBoard)
+	require.Contains(t, recorder.raw, Event{Kind: EventRunItTwice, Cards: first, Index: 1})
+	require.Contains(t, recorder.raw, Event{Kind: EventRunItTwice, Cards: second, Index: 2})
+	require.Equal(t, []PotResult{
+		{Amount: 1000, Winners: []string{p2.GetId()}, Margin: table.Meta.ShowdownResults[0].Margin},
+		{Amount: 1000, Winners: []string{p1.GetId()}, Margin: table.Meta.ShowdownResults[1].Margin},
+	}, table.Meta.ShowdownResults)
+	require.Equal(t, 1000, p1.GetBalance())
+	require.Equal(t, 1000, p2.GetBalance())
+
+	// без олл-ина борд раздается один раз
+	config.Shuffler = nil
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+	require.Empty(t, table.Meta.SecondBoard)
+
+	// колоды должно хватать на оба борда
+	config.HoleCards = 15
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p3))
+	require.ErrorIs(t, table.StartGame(), ErrNotEnoughCards)
+	config.RunItTwice = false
+	require.NoError(t, table.StartGame())
+}
+
+func TestStraddle(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p := []*Player{}
+	for i := 1; i <= 4; i++ {
+		p = append(p, &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000})
+		require.NoError(t, table.AddPlayer(p[i-1]))
+	}
+	require.ErrorIs(t, table.PostStraddle(p[0].GetId(), 200), ErrGameNotStarted)
+
+	// дилер p2, блайнды p3 и p4, страддл ставит p1
+	require.NoError(t, table.StartGame())
+	require.ErrorIs(t, table.PostStraddle(p[1].GetId(), 200), ErrStraddleNotAllowed)
+	require.ErrorIs(t, table.PostStraddle(p[0].GetId(), 150), ErrInvalidStraddle)
+	require.NoError(t, table.PostStraddle(p[0].GetId(), 200))
+	require.ErrorIs(t, table.PostStraddle(p[0].GetId(), 200), ErrStraddleNotAllowed)
+	require.Contains(t, recorder.events, "Player 00000000-0000-0000-0000-000000000001 bet 200 as straddle")
+	require.Equal(t, 200, table.Meta.CurrentBet)
+	require.Equal(t, 800, p[0].GetBalance())
+	require.Equal(t, p[1].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+	require.ErrorIs(t, table.MakeMove(p[1].GetId(), "raise", 300), ErrRaiseTooSmall)
+
+	require.NoError(t, table.MakeMove(p[1].GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p[2].GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p[3].GetId(), "call", 0))
+	// страддл ходит последним и может повысить
+	require.Equal(t, RoundPreflop, table.Meta.CurrentRound)
+	require.Equal(t, p[0].GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+	require.NoError(t, table.MakeMove(p[0].GetId(), "check", 0))
+	require.Equal(t, RoundFlop, table.Meta.CurrentRound)
+	require.Equal(t, 800, table.totalPot())
+	playHand(t, table)
+
+	require.NoError(t, table.StartGame())
+	require.Empty(t, table.Meta.Straddle)
+}
+
+func TestPotDetails(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 300}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	all := []string{p1.GetId(), p2.GetId(), p3.GetId()}
+	committed := func() int { return 2300 - p1.GetBalance() - p2.GetBalance() - p3.GetBalance() }
+	total := func(details []PotInfo) int {
+		sum := 0
+		for _, d := range details {
+			sum += d.Amount
+		}
+		return sum
+	}
+
+	require.NoError(t, table.StartGame())
+	require.Equal(t, 150, table.TotalPot())
+	require.Equal(t, []PotInfo{{Amount: 150, Applicants: all, Label: "main"}}, table.PotDetails())
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "raise", 400))
+	require.NoError(t, table.MakeMove(p3.GetId(), "allin", 0))
+	require.Equal(t, committed(), table.TotalPot())
+	require.Equal(t, []PotInfo{
+		{Amount: 700, Applicants: all, Label: "main"},
+		{Amount: 100, Applicants: all[:2], Label: "side 1"},
+	}, table.PotDetails())
+
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.Equal(t, RoundFlop, table.Meta.CurrentRound)
+	require.Equal(t, 1100, table.TotalPot())
+	require.Equal(t, []PotInfo{
+		{Amount: 900, Applicants: all, Label: "main"},
+		{Amount: 200, Applicants: all[:2], Label: "side 1"},
+	}, table.PotDetails())
+
+	// ставка на флопе ложится в побочный банк тех же игроков
+	require.NoError(t, table.MakeMove(p1.GetId(), "bet", 100))
+	require.Equal(t, committed(), table.TotalPot())
+	details := table.PotDetails()
+	require.Equal(t, []PotInfo{
+		{Amount: 900, Applicants: all, Label: "main"},
+		{Amount: 300, Applicants: all[:2], Label: "side 1"},
+	}, details)
+	require.Equal(t, table.TotalPot(), total(details))
+
+	require.NoError(t, table.MakeMove(p2.GetId(), "fold", 0))
+	require.Zero(t, table.TotalPot())
+	require.Equal(t, 2300, p1.GetBalance()+p2.GetBalance()+p3.GetBalance())
+}
+
+func TestBetWholeStackAdvancesRound(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 300}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.Equal(t, RoundFlop, table.Meta.CurrentRound)
+
+	// ставка на весь остаток обычным bet делает игрока олл-ин
+	require.NoError(t, table.MakeMove(p3.GetId(), "bet", 200))
+	require.Zero(t, p3.GetBalance())
+	require.True(t, p3.IsAllIn())
+	require.NoError(t, table.MakeMove(p1.GetId(), "call", 0))
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.Equal(t, RoundTurn, table.Meta.CurrentRound)
+	require.Equal(t, p1.GetId(), table.Meta.PlayersOrder[table.Meta.PlayerTurnInd])
+
+	// игрок без фишек, не помеченный олл-ин, тоже не держит улицу
+	p3.SetAllIn(false)
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+	require.NoError(t, table.MakeMove(p2.GetId(), "check", 0))
+	require.Equal(t, RoundRiver, table.Meta.CurrentRound)
+}
+
+func TestNoNextPlayerClosesStreet(t *testing.T) {
+	newTable := func(n int) (*PokerTable, []*Player) {
+		meta := NewTableMeta(50, 0, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		table := NewPokerTable(config, meta)
+		p := []*Player{}
+		for i := 1; i <= n; i++ {
+			p = append(p, &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000})
+			require.NoError(t, table.AddPlayer(p[i-1]))
+		}
+		require.NoError(t, table.StartGame())
+		return table, p
+	}
+
+	t.Run("heads-up fold", func(t *testing.T) {
+		table, p := newTable(2)
+		require.NoError(t, table.MakeMove(p[1].GetId(), "fold", 0))
+		require.False(t, table.Meta.GameStarted)
+		require.Equal(t, 1050, p[0].GetBalance())
+	})
+
+	t.Run("heads-up all-in", func(t *testing.T) {
+		table, p := newTable(2)
+		require.NoError(t, table.MakeMove(p[1].GetId(), "allin", 0))
+		require.NoError(t, table.MakeMove(p[0].GetId(), "call", 0))
+		require.False(t, table.Meta.GameStarted)
+		require.Len(t, table.Meta.CommunityCards, 5)
+		require.Equal(t, 2000, p[0].GetBalance()+p[1].GetBalance())
+	})
+
+	t.Run("all opponents all-in", func(t *testing.T) {
+		table, p := newTable(3)
+		require.NoError(t, table.MakeMove(p[1].GetId(), "allin", 0))
+		require.NoError(t, table.MakeMove(p[2].GetId(), "allin", 0))
+		require.NoError(t, table.MakeMove(p[0].GetId(), "call", 0))
+		require.False(t, table.Meta.GameStarted)
+		require.Len(t, table.Meta.CommunityCards, 5)
+		require.Equal(t, 3000, p[0].GetBalance()+p[1].GetBalance()+p[2].GetBalance())
+	})
+
+	t.Run("nobody left to act", func(t *testing.T) {
+		table, p := newTable(3)
+		turn := table.Meta.PlayerTurnInd
+		for _, v := range p {
+			v.SetStatus(true)
+		}
+		require.False(t, table.getNextPlayer())
+		require.Equal(t, turn, table.Meta.PlayerTurnInd)
+	})
+}
+
+func TestRemoveObserver(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	common, seated, kept := &eventRecorder{}, &eventRecorder{}, &eventRecorder{}
+	table.AddObserver(common)
+	table.AddObserver(kept)
+	table.AddPlayerObserver(p1.GetId(), seated)
+	require.NoError(t, table.AddPlayer(p1))
+
+	require.True(t, table.RemoveObserver(common))
+	require.True(t, table.RemoveObserver(seated))
+	require.False(t, table.RemoveObserver(common))
+	commonEvents, seatedEvents := len(common.events), len(seated.events)
+
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+	require.Len(t, common.events, commonEvents)
+	require.Len(t, seated.events, seatedEvents)
+	require.Contains(t, kept.events, "Game started")
+}
+
+// observerFunc is an observer of a type that can't be compared
+type observerFunc func(Event)
+
+func (f observerFunc) Update(event Event) {
+	f(event)
+}
+
+func TestRemoveObserverFunc(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	common, seated, kept := 0, 0, 0
+	commonObs := observerFunc(func(Event) { common++ })
+	removeCommon := table.AddObserver(commonObs)
+	removeSeated := table.AddPlayerObserver(p1.GetId(), observerFunc(func(Event) { seated++ }))
+	table.AddObserver(observerFunc(func(Event) { kept++ }))
+	require.NoError(t, table.AddPlayer(p1))
+
+	require.False(t, table.RemoveObserver(commonObs)) // функции не сравниваются, снимаются только по подписке
+	require.True(t, removeCommon())
+	require.True(t, removeSeated())
+	require.False(t, removeCommon())
+	commonEvents, seatedEvents, keptEvents := common, seated, kept
+
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.StartGame())
+	require.Equal(t, commonEvents, common)
+	require.Equal(t, seatedEvents, seated)
+	require.Greater(t, kept, keptEvents)
+}
+
+// gatedObserver stalls every event until the gate is opened
+type gatedObserver struct {
+	gate chan struct{}
+	eventRecorder
+}
+
+func (g *gatedObserver) Update(event Event) {
+	<-g.gate
+	g.eventRecorder.Update(event)
+}
+
+func TestAsyncObserver(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	recorder := &eventRecorder{}
+	slow := &gatedObserver{gate: make(chan struct{})}
+	dropping := NewAsyncObserver(slow, 4, DropOnFull)
+	fast := &eventRecorder{}
+	buffered := NewAsyncObserver(fast, 1, BlockOnFull)
+	table.AddObserver(recorder)
+	table.AddObserver(dropping)
+	table.AddObserver(buffered)
+
+	// медленный наблюдатель не успевает ничего разобрать, но раздача доигрывается
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+	require.False(t, table.Meta.GameStarted)
+	require.Positive(t, dropping.Dropped())
+
+	close(slow.gate)
+	dropping.Close()
+	buffered.Close()
+	require.Len(t, slow.events, len(recorder.events)-dropping.Dropped())
+	// отброшенные события могут быть и в середине, если очередь успела освободиться, порядок остальных сохраняется
+	rest := recorder.events
+	for _, e := range slow.events {
+		i := slices.Index(rest, e)
+		require.GreaterOrEqual(t, i, 0, e)
+		rest = rest[i+1:]
+	}
+	require.Equal(t, recorder.events, fast.events)
+
+	// после Close события больше не принимаются
+	table.AddObserver(buffered)
+	require.NoError(t, table.StartGame())
+	require.Equal(t, recorder.events[:len(fast.events)], fast.events)
+	require.Less(t, len(fast.events), len(recorder.events))
+}
+
+func TestTurnPrompt(t *testing.T) {
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	p3 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000003"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+	require.NoError(t, table.AddPlayer(p3))
+	lastTurn := func() Event {
+		for i := len(recorder.raw) - 1; i >= 0; i-- {
+			if recorder.raw[i].Kind == EventTurn {
+				return recorder.raw[i]
+			}
+		}
+		return Event{}
+	}
+
+	require.NoError(t, table.StartGame())
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	// малый блайнд уже поставил 50 из 100
+	require.Equal(t, Event{Kind: EventTurn, PlayerID: p3.GetId(), Amount: 50, Pot: 250, MinRaise: 200, MaxRaise: 1000}, lastTurn())
+	require.NoError(t, table.MakeMove(p3.GetId(), "call", 0))
+	require.Equal(t, Event{Kind: EventTurn, PlayerID: p1.GetId(), Amount: 0, Pot: 300, MinRaise: 200, MaxRaise: 1000}, lastTurn())
+	require.Equal(t, "player 00000000-0000-0000-0000-000000000001 can do check (pot 300, bet 200-1000)", recorder.events[len(recorder.events)-1])
+	require.NoError(t, table.MakeMove(p1.GetId(), "check", 0))
+
+	require.NoError(t, table.MakeMove(p3.GetId(), "bet", 100))
+	require.NoError(t, table.MakeMove(p1.GetId(), "raise", 300))
+	require.Equal(t, Event{Kind: EventTurn, PlayerID: p2.GetId(), Amount: 300, Round: RoundFlop, Pot: 700, MinRaise: 500, MaxRaise: 900}, lastTurn())
+	require.NoError(t, table.MakeMove(p2.GetId(), "call", 0))
+	require.Equal(t, Event{Kind: EventTurn, PlayerID: p3.GetId(), Amount: 200, Round: RoundFlop, Pot: 1000, MinRaise: 500, MaxRaise: 900}, lastTurn())
+}
+
+func TestWinnerHand(t *testing.T) {
+	kingQueen := []Card{{Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 12}}
+	aces := []Card{{Suit: "Hearts", Value: 14}, {Suit: "Diamonds", Value: 14}}
+	board := []Card{{Suit: "Spades", Value: 9}, {Suit: "Spades", Value: 4}, {Suit: "Spades", Value: 2}, {Suit: "Hearts", Value: 7}, {Suit: "Diamonds", Value: 11}}
+	meta := NewTableMeta(50, 0, 1488)
+	config := NewTableConfig(time.Hour, 10, 2, -1, false)
+	config.Shuffler = &stackedShuffler{tops: [][]Card{slices.Concat(kingQueen, aces, board)}}
+	table := NewPokerTable(config, meta)
+	recorder := &eventRecorder{}
+	table.AddObserver(recorder)
+	p1 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 1000}
+	p2 := &Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 1000}
+	require.NoError(t, table.AddPlayer(p1))
+	require.NoError(t, table.AddPlayer(p2))
+
+	require.NoError(t, table.StartGame())
+	playHand(t, table)
+
+	idx := slices.IndexFunc(recorder.raw, func(e Event) bool { return e.Kind == EventWinner })
+	require.NotEqual(t, -1, idx)
+	winner := recorder.raw[idx]
+	require.Equal(t, []string{p1.GetId()}, winner.Players)
+	require.Equal(t, Flush, winner.Rank)
+	require.Equal(t, map[string][]Card{p1.GetId(): {
+		{Suit: "Spades", Value: 13}, {Suit: "Spades", Value: 12}, {Suit: "Spades", Value: 9}, {Suit: "Spades", Value: 4}, {Suit: "Spades", Value: 2},
+	}}, winner.Hands)
+	require.Contains(t, winner.String(), "with "+Flush.String())
+}
+
+func TestSeedEvolution(t *testing.T) {
+	// раздает три раздачи и возвращает колоды каждой из них
+	decks := func(deterministic bool) [][]Card {
+		meta := NewTableMeta(50, 0, 1488)
+		config := NewTableConfig(time.Hour, 10, 2, -1, false)
+		config.Deterministic = deterministic
+		table := NewPokerTable(config, meta)
+		require.NoError(t, table.AddPlayer(&Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Balance: 100000}))
+		require.NoError(t, table.AddPlayer(&Player{Id: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Balance: 100000}))
+		result := [][]Card{}
+		for range 3 {
+			require.NoError(t, table.StartGame())
+			result = append(result, slices.Clone(table.Meta.Deck))
+			playHand(t, table)
+		}
+		return result
+	}
+
+	require.Equal(t, decks(true), decks(true))
+
+	first, second := decks(false), decks(false)
+	require.Equal(t, first[0], second[0], "the first hand is dealt from the given seed")
+	require.NotEqual(t, first[1], second[1])
+	require.NotEqual(t, first[2], second[2])
+}
+
+func TestTurnIntrospection(t *testing.T) {
+	tests := []struct {
+		players                        int
+		dealer, small, big, firstToAct int
+	}{
+		{players: 2, dealer: 2, small: 2, big: 1, firstToAct: 2}, // в хендз апе дилер ставит малый блайнд и ходит первым
+		{players: 3, dealer: 2, small: 3, big: 1, firstToAct: 2},
+		{players: 6, dealer: 2, small: 3, big: 4, firstToAct: 5},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d players", tt.players), func(t *testing.T) {
+			meta := NewTableMeta(50, 0, 1488)
+			config := NewTableConfig(time.Hour, 10, 2, -1, false)
+			table := NewPokerTable(config, meta)
+			ids := []string{}
+			for i := 1; i <= tt.players; i++ {
+				p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000}
+				require.NoError(t, table.AddPlayer(p))
+				ids = append(ids, p.GetId())
+			}
+			_, err := table.CurrentPlayer()
+			require.ErrorIs(t, err, ErrGameNotStarted)
+			require.Empty(t, table.DealerID())
+
+			require.NoError(t, table.StartGame())
+			require.Equal(t, ids[tt.dealer-1], table.DealerID())
+			require.Equal(t, ids[tt.small-1], table.SmallBlindID())
+			require.Equal(t, ids[tt.big-1], table.BigBlindID())
+			current, err := table.CurrentPlayer()
+			require.NoError(t, err)
+			require.Equal(t, ids[tt.firstToAct-1], current)
+
+			require.NoError(t, table.MakeMove(current, "call", 0))
+			next, err := table.CurrentPlayer()
+			require.NoError(t, err)
+			require.Equal(t, table.Meta.PlayersOrder[table.Meta.PlayerTurnInd], next)
+			require.NotEqual(t, current, next)
+
+			playHand(t, table)
+			_, err = table.CurrentPlayer()
+			require.ErrorIs(t, err, ErrGameNotStarted)
+			require.Equal(t, ids[tt.big-1], table.BigBlindID(), "positions of the last hand stay available")
+		})
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *TableConfig
+		meta   *TableMeta
+		err    error
+	}{
+		{name: "valid", config: NewTableConfig(time.Hour, 10, 2, -1, false), meta: NewTableMeta(50, 10, 1488)},
+		{name: "heads-up only", config: NewTableConfig(time.Hour, 2, 2, -1, false), meta: NewTableMeta(0, 0, 1488)},
+		{name: "max players below two", config: NewTableConfig(time.Hour, 1, 1, -1, false), meta: NewTableMeta(50, 0, 1488), err: ErrInvalidMaxPlayers},
+		{name: "min above max", config: NewTableConfig(time.Hour, 6, 7, -1, false), meta: NewTableMeta(50, 0, 1488), err: ErrInvalidMinPlayers},
+		{name: "negative min players", config: NewTableConfig(time.Hour, 6, -1, -1, false), meta: NewTableMeta(50, 0, 1488), err: ErrInvalidMinPlayers},
+		{name: "negative small blind", config: NewTableConfig(time.Hour, 10, 2, -1, false), meta: NewTableMeta(-50, 0, 1488), err: ErrInvalidBlind},
+		{name: "negative ante", config: NewTableConfig(time.Hour, 10, 2, -1, false), meta: NewTableMeta(50, -10, 1488), err: ErrInvalidAnte},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if err == nil {
+				err = tt.meta.Validate()
+			}
+			require.ErrorIs(t, err, tt.err)
+
+			// стол с такими настройками не начинает игру
+			table := NewPokerTable(tt.config, tt.meta)
+			for i := 1; i <= 2; i++ {
+				p := &Player{Id: uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-00000000000%d", i)), Balance: 1000}
+				table.Meta.Players[p.GetId()] = p
+				table.Meta.PlayersOrder = append(table.Meta.PlayersOrder, p.GetId())
+			}
+			require.ErrorIs(t, table.StartGame(), tt.err)
+		})
+	}
+}
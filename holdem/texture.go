@@ -0,0 +1,55 @@
+package holdem
+
+// Texture describes how coordinated the community cards are.
+// A board that is not Wet is considered dry.
+type Texture struct {
+	Paired    bool // на борде есть карты одного достоинства
+	Monotone  bool // все карты одной масти
+	TwoTone   bool // ровно две карты одной масти, флеш еще не собран
+	Rainbow   bool // все карты разных мастей
+	Connected bool // три карты укладываются в стрит
+	Wet       bool // возможен стрит или флеш
+}
+
+// BoardTexture classifies the community cards. It returns an empty Texture for less than three cards.
+func BoardTexture(community []Card) Texture {
+	if len(community) < 3 {
+		return Texture{}
+	}
+
+	suits := make(map[string]int)
+	values := make(map[int]bool)
+	paired := false
+	maxSuit := 0
+	for _, c := range community {
+		suits[c.Suit]++
+		maxSuit = max(maxSuit, suits[c.Suit])
+		if values[c.Value] {
+			paired = true
+		}
+		values[c.Value] = true
+	}
+	if values[14] { // туз участвует и в младшем стрите
+		values[1] = true
+	}
+
+	connected := false
+	for low := 1; low <= 10 && !connected; low++ { // ищем окно из пяти достоинств с тремя картами
+		count := 0
+		for v := low; v < low+5; v++ {
+			if values[v] {
+				count++
+			}
+		}
+		connected = count >= 3
+	}
+
+	return Texture{
+		Paired:    paired,
+		Monotone:  len(suits) == 1,
+		TwoTone:   maxSuit == 2,
+		Rainbow:   maxSuit == 1,
+		Connected: connected,
+		Wet:       connected || maxSuit >= 3,
+	}
+}
@@ -0,0 +1,47 @@
+package holdem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardTexture(t *testing.T) {
+	cases := []struct {
+		TestCaseName string
+		Board        []Card
+		Expected     Texture
+	}{
+		{
+			TestCaseName: "monotone connected",
+			Board:        []Card{{Suit: "Hearts", Value: 9}, {Suit: "Hearts", Value: 8}, {Suit: "Hearts", Value: 7}},
+			Expected:     Texture{Monotone: true, Connected: true, Wet: true},
+		},
+		{
+			TestCaseName: "rainbow disconnected",
+			Board:        []Card{{Suit: "Hearts", Value: 13}, {Suit: "Clubs", Value: 7}, {Suit: "Spades", Value: 2}},
+			Expected:     Texture{Rainbow: true},
+		},
+		{
+			TestCaseName: "paired two tone",
+			Board:        []Card{{Suit: "Hearts", Value: 13}, {Suit: "Hearts", Value: 2}, {Suit: "Spades", Value: 2}},
+			Expected:     Texture{Paired: true, TwoTone: true},
+		},
+		{
+			TestCaseName: "wheel draw",
+			Board:        []Card{{Suit: "Hearts", Value: 14}, {Suit: "Clubs", Value: 2}, {Suit: "Spades", Value: 4}},
+			Expected:     Texture{Rainbow: true, Connected: true, Wet: true},
+		},
+		{
+			TestCaseName: "preflop",
+			Board:        []Card{},
+			Expected:     Texture{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.TestCaseName, func(t *testing.T) {
+			require.Equal(t, c.Expected, BoardTexture(c.Board))
+		})
+	}
+}